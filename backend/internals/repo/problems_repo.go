@@ -1,151 +1,315 @@
-package repo
-
-import (
-	"algo-arena-be/internals/models"
-	"context"
-	"database/sql"
-	"fmt"
-	"time"
-)
-
-const acceptedStatus = "Accepted"
-
-type problemRepo struct {
-	db *sql.DB
-}
-
-// GetProblems returns a list of problems with user-specific IsSolved flags.
-func (r *problemRepo) GetProblems(ctx context.Context, userId int) ([]models.ProblemInfo, error) {
-	ctx, cancel := context.WithTimeout(ctx, maxQuerySeconds*time.Second)
-	defer cancel()
-
-	query := `
-		SELECT
-			p.id,
-			p.title,
-			p.acceptance_rate,
-			d.id,
-			d.name,
-			EXISTS (
-				SELECT 1
-				FROM code_submissions cs
-				JOIN status_ids s ON s.id = cs.status_id
-				WHERE cs.problem_id = p.id AND cs.user_id = $1 AND s.name = $2
-			) AS is_solved
-		FROM problems p
-		LEFT JOIN difficulties d ON p.difficulty_id = d.id
-	`
-
-	rows, err := r.db.QueryContext(ctx, query, userId, acceptedStatus)
-	if err != nil {
-		return nil, fmt.Errorf("querying problems: %w", err)
-	}
-	defer rows.Close()
-
-	var problems []models.ProblemInfo
-	for rows.Next() {
-		var p models.ProblemInfo
-		var difficulty models.Difficulty
-		accRate := 0.0
-		err := rows.Scan(
-			&p.ID, &p.Title, &accRate,
-			&difficulty.ID, &difficulty.Name, &p.IsSolved,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("scanning problem row: %w", err)
-		}
-
-		p.AcceptanceRate = int(accRate * 100)
-		p.Difficulty = difficulty
-		p.Tags = []models.Tag{} // not loading tags here for performance
-		problems = append(problems, p)
-	}
-
-	return problems, nil
-}
-
-// ViewProblem returns detailed info about a specific problem for the user.
-func (r *problemRepo) ViewProblem(ctx context.Context, userId, problemId int) (*models.ProblemDetail, error) {
-	ctx, cancel := context.WithTimeout(ctx, maxQuerySeconds*time.Second)
-	defer cancel()
-
-	// Fetch main problem data
-	query := `
-		SELECT 
-			p.id, p.title, p.description, p.acceptance_rate, p.constraints,
-			d.id, d.name,
-			EXISTS (
-				SELECT 1
-				FROM code_submissions cs
-				JOIN status_ids s ON s.id = cs.status_id
-				WHERE cs.problem_id = p.id AND cs.user_id = $1 AND s.name = $2
-			) AS is_solved
-		FROM problems p
-		LEFT JOIN difficulties d ON p.difficulty_id = d.id
-		WHERE p.id = $3
-	`
-
-	var detail models.ProblemDetail
-	var difficulty models.Difficulty
-
-	accRate := 0.0
-
-	err := r.db.QueryRowContext(ctx, query, userId, acceptedStatus, problemId).Scan(
-		&detail.ID, &detail.Title, &detail.Description,
-		&accRate, &detail.Constraints,
-		&difficulty.ID, &difficulty.Name, &detail.IsSolved,
-	)
-	// if err == sql.ErrNoRows {
-	// 	return nil, nil
-	// }
-	if err != nil {
-		return nil, fmt.Errorf("fetching problem detail: %w", err)
-	}
-
-	detail.AcceptanceRate = int(accRate * 100)
-
-	detail.Difficulty = difficulty
-
-	// Fetch tags
-	tagsQuery := `
-		SELECT t.id, t.name
-		FROM tags t
-		JOIN problem_tags pt ON pt.tag_id = t.id
-		WHERE pt.problem_id = $1
-	`
-	tagRows, err := r.db.QueryContext(ctx, tagsQuery, problemId)
-	if err != nil {
-		return nil, fmt.Errorf("fetching tags: %w", err)
-	}
-	defer tagRows.Close()
-
-	for tagRows.Next() {
-		var tag models.Tag
-		if err := tagRows.Scan(&tag.ID, &tag.Name); err != nil {
-			return nil, fmt.Errorf("scanning tag: %w", err)
-		}
-		detail.Tags = append(detail.Tags, tag)
-	}
-
-	// Fetch examples
-	exampleQuery := `
-		SELECT id, input, expected_output, explanation
-		FROM problem_examples
-		WHERE problem_id = $1
-	`
-	exampleRows, err := r.db.QueryContext(ctx, exampleQuery, problemId)
-	if err != nil {
-		return nil, fmt.Errorf("fetching examples: %w", err)
-	}
-	defer exampleRows.Close()
-
-	for exampleRows.Next() {
-		var ex models.ProblemExample
-		if err := exampleRows.Scan(&ex.ID, &ex.Input, &ex.Output, &ex.Explanation); err != nil {
-			return nil, fmt.Errorf("scanning example: %w", err)
-		}
-		detail.Examples = append(detail.Examples, ex)
-	}
-
-	return &detail, nil
-}
+package repo
+
+import (
+	"algo-arena-be/internals/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const acceptedStatus = "Accepted"
+
+// acceptanceRateSQL computes a problem's acceptance rate (0..1) from the counters
+// maintained by submissionRepo as submissions are judged.
+const acceptanceRateSQL = `CASE WHEN p.total_submissions = 0 THEN 0
+	ELSE p.accepted_submissions::numeric / p.total_submissions END`
+
+// escapedHTMLSQL HTML-escapes a text column, so that ts_headline output can be rendered
+// with only the <mark> tags it adds being markup.
+func escapedHTMLSQL(column string) string {
+	return `replace(replace(replace(replace(` + column + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')`
+}
+
+// isSolvedSQL tells whether the user bound to $1 has solved problem p.
+const isSolvedSQL = `EXISTS (
+	SELECT 1
+	FROM user_problem_stats ups
+	WHERE ups.problem_id = p.id AND ups.user_id = $1 AND ups.solved_at IS NOT NULL
+)`
+
+type problemRepo struct {
+	db *sql.DB
+}
+
+// problemSortColumns maps the accepted sort keys to ORDER BY expressions.
+var problemSortColumns = map[string]string{
+	"id":         "id",
+	"acceptance": "acceptance_rate",
+	"difficulty": "difficulty_id",
+}
+
+// GetProblems returns a filtered, sorted page of problems with user-specific IsSolved flags.
+func (r *problemRepo) GetProblems(ctx context.Context, userId int, query models.ProblemListQuery) (*models.ProblemListPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, maxQuerySeconds*time.Second)
+	defer cancel()
+
+	base := `
+		WITH listed AS (
+			SELECT
+				p.id,
+				p.title,
+				` + acceptanceRateSQL + ` AS acceptance_rate,
+				d.id AS difficulty_id,
+				d.name AS difficulty_name,
+				` + isSolvedSQL + ` AS is_solved
+			FROM problems p
+			LEFT JOIN difficulties d ON p.difficulty_id = d.id
+		)
+	`
+	args := []any{userId}
+	var conditions []string
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if query.Difficulty != "" {
+		conditions = append(conditions, "LOWER(difficulty_name) = LOWER("+arg(query.Difficulty)+")")
+	}
+	if query.Search != "" {
+		conditions = append(conditions, "title ILIKE '%' || "+arg(escapeLike(query.Search))+" || '%'")
+	}
+	if query.Solved != nil {
+		conditions = append(conditions, "is_solved = "+arg(*query.Solved))
+	}
+	if len(query.Tags) > 0 {
+		tags := make([]string, len(query.Tags))
+		for i, t := range query.Tags {
+			tags[i] = strings.ToLower(t)
+		}
+		tagCount := `(
+			SELECT COUNT(DISTINCT LOWER(t.name))
+			FROM problem_tags pt
+			JOIN tags t ON t.id = pt.tag_id
+			WHERE pt.problem_id = listed.id AND LOWER(t.name) = ANY(` + arg(pq.Array(tags)) + `)
+		)`
+		if query.MatchAllTag {
+			conditions = append(conditions, tagCount+" = "+arg(len(tags)))
+		} else {
+			conditions = append(conditions, tagCount+" > 0")
+		}
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	page := &models.ProblemListPage{
+		Problems: []models.ProblemInfo{},
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+
+	if err := r.db.QueryRowContext(ctx, base+"SELECT COUNT(*) FROM listed"+where, args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("counting problems: %w", err)
+	}
+	page.TotalPages = (page.Total + query.PageSize - 1) / query.PageSize
+
+	sortColumn, ok := problemSortColumns[query.SortBy]
+	if !ok {
+		sortColumn = "id"
+	}
+	direction := "ASC"
+	if query.Descending {
+		direction = "DESC"
+	}
+
+	selectQuery := base + `
+		SELECT id, title, acceptance_rate, difficulty_id, difficulty_name, is_solved
+		FROM listed` + where + `
+		ORDER BY ` + sortColumn + ` ` + direction + ` NULLS LAST, id ` + direction + `
+		LIMIT ` + arg(query.PageSize) + ` OFFSET ` + arg((query.Page-1)*query.PageSize)
+
+	rows, err := r.db.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying problems: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.ProblemInfo
+		var difficulty models.Difficulty
+		accRate := 0.0
+		err := rows.Scan(
+			&p.ID, &p.Title, &accRate,
+			&difficulty.ID, &difficulty.Name, &p.IsSolved,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning problem row: %w", err)
+		}
+
+		p.AcceptanceRate = int(accRate * 100)
+		p.Difficulty = difficulty
+		p.Tags = []models.Tag{} // not loading tags here for performance
+		page.Problems = append(page.Problems, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating problem rows: %w", err)
+	}
+
+	return page, nil
+}
+
+// SearchProblems ranks problems against a web-search style query (quoted phrases, "or", -exclusion)
+// using the search_vector column, and highlights the matches in the title and a description snippet.
+func (r *problemRepo) SearchProblems(ctx context.Context, userId int, text string, page, pageSize int) (*models.ProblemSearchPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, maxQuerySeconds*time.Second)
+	defer cancel()
+
+	result := &models.ProblemSearchPage{
+		Query:    text,
+		Hits:     []models.ProblemSearchHit{},
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	countQuery := `
+		SELECT COUNT(*)
+		FROM problems p
+		WHERE p.search_vector @@ websearch_to_tsquery('english', $1)
+	`
+	if err := r.db.QueryRowContext(ctx, countQuery, text).Scan(&result.Total); err != nil {
+		return nil, fmt.Errorf("counting search results: %w", err)
+	}
+	result.TotalPages = (result.Total + pageSize - 1) / pageSize
+
+	query := `
+		WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query)
+		SELECT
+			p.id,
+			p.title,
+			` + acceptanceRateSQL + `,
+			d.id,
+			d.name,
+			` + strings.ReplaceAll(isSolvedSQL, "$1", "$2") + ` AS is_solved,
+			ts_rank_cd(p.search_vector, q.query) AS rank,
+			ts_headline('english', ` + escapedHTMLSQL("p.title") + `, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+			ts_headline('english', ` + escapedHTMLSQL("p.description") + `, q.query,
+				'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "')
+		FROM problems p
+		CROSS JOIN q
+		LEFT JOIN difficulties d ON p.difficulty_id = d.id
+		WHERE p.search_vector @@ q.query
+		ORDER BY rank DESC, p.id
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, text, userId, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("searching problems: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hit models.ProblemSearchHit
+		accRate := 0.0
+		err := rows.Scan(
+			&hit.ID, &hit.Title, &accRate,
+			&hit.Difficulty.ID, &hit.Difficulty.Name, &hit.IsSolved,
+			&hit.Rank, &hit.TitleHighlight, &hit.Snippet,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning search row: %w", err)
+		}
+
+		hit.AcceptanceRate = int(accRate * 100)
+		hit.Tags = []models.Tag{}
+		result.Hits = append(result.Hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating search rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// ViewProblem returns detailed info about a specific problem for the user.
+func (r *problemRepo) ViewProblem(ctx context.Context, userId, problemId int) (*models.ProblemDetail, error) {
+	ctx, cancel := context.WithTimeout(ctx, maxQuerySeconds*time.Second)
+	defer cancel()
+
+	// Fetch main problem data
+	query := `
+		SELECT 
+			p.id, p.title, p.description, ` + acceptanceRateSQL + `, p.constraints,
+			d.id, d.name,
+			` + isSolvedSQL + ` AS is_solved
+		FROM problems p
+		LEFT JOIN difficulties d ON p.difficulty_id = d.id
+		WHERE p.id = $2
+	`
+
+	var detail models.ProblemDetail
+	var difficulty models.Difficulty
+
+	accRate := 0.0
+
+	err := r.db.QueryRowContext(ctx, query, userId, problemId).Scan(
+		&detail.ID, &detail.Title, &detail.Description,
+		&accRate, &detail.Constraints,
+		&difficulty.ID, &difficulty.Name, &detail.IsSolved,
+	)
+	// if err == sql.ErrNoRows {
+	// 	return nil, nil
+	// }
+	if err != nil {
+		return nil, fmt.Errorf("fetching problem detail: %w", err)
+	}
+
+	detail.AcceptanceRate = int(accRate * 100)
+
+	detail.Difficulty = difficulty
+
+	// Fetch tags
+	tagsQuery := `
+		SELECT t.id, t.name
+		FROM tags t
+		JOIN problem_tags pt ON pt.tag_id = t.id
+		WHERE pt.problem_id = $1
+	`
+	tagRows, err := r.db.QueryContext(ctx, tagsQuery, problemId)
+	if err != nil {
+		return nil, fmt.Errorf("fetching tags: %w", err)
+	}
+	defer tagRows.Close()
+
+	for tagRows.Next() {
+		var tag models.Tag
+		if err := tagRows.Scan(&tag.ID, &tag.Name); err != nil {
+			return nil, fmt.Errorf("scanning tag: %w", err)
+		}
+		detail.Tags = append(detail.Tags, tag)
+	}
+
+	// Fetch examples
+	exampleQuery := `
+		SELECT id, input, expected_output, explanation
+		FROM problem_examples
+		WHERE problem_id = $1
+	`
+	exampleRows, err := r.db.QueryContext(ctx, exampleQuery, problemId)
+	if err != nil {
+		return nil, fmt.Errorf("fetching examples: %w", err)
+	}
+	defer exampleRows.Close()
+
+	for exampleRows.Next() {
+		var ex models.ProblemExample
+		if err := exampleRows.Scan(&ex.ID, &ex.Input, &ex.Output, &ex.Explanation); err != nil {
+			return nil, fmt.Errorf("scanning example: %w", err)
+		}
+		detail.Examples = append(detail.Examples, ex)
+	}
+
+	return &detail, nil
+}
@@ -1,59 +1,174 @@
-package services
-
-import (
-	"algo-arena-be/internals/repo"
-	"log"
-	"net/http"
-	"strconv"
-
-	"github.com/go-chi/chi/v5"
-)
-
-type problemService struct {
-	repo repo.ProblemRepo
-}
-
-func (s *problemService) GetProblems(w http.ResponseWriter, r *http.Request) {
-	userID, ok := withUserID(r)
-	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	problems, err := s.repo.GetProblems(r.Context(), userID)
-	if err != nil {
-		log.Println("Failed to fetch problems: ", err)
-		http.Error(w, "Failed to fetch problems", http.StatusInternalServerError)
-		return
-	}
-
-	writeJSON(w, http.StatusOK, problems)
-}
-
-func (s *problemService) ViewProblem(w http.ResponseWriter, r *http.Request) {
-	userID, ok := withUserID(r)
-	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	problemIDStr := chi.URLParam(r, "problemId")
-	problemID, err := strconv.Atoi(problemIDStr)
-	if err != nil {
-		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
-		return
-	}
-
-	problem, err := s.repo.ViewProblem(r.Context(), userID, problemID)
-	if err != nil {
-		log.Println("Failed to fetch problem: ", err)
-		http.Error(w, "Failed to fetch problem", http.StatusInternalServerError)
-		return
-	}
-	if problem == nil {
-		http.NotFound(w, r)
-		return
-	}
-
-	writeJSON(w, http.StatusOK, problem)
-}
+package services
+
+import (
+	"algo-arena-be/internals/models"
+	"algo-arena-be/internals/repo"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type problemService struct {
+	repo repo.ProblemRepo
+}
+
+const (
+	defaultProblemPageSize = 20
+	maxProblemPageSize     = 100
+)
+
+// parseProblemListQuery reads the filters, sorting and pagination of GET /problems:
+// ?difficulty=easy&tags=array,graph&tag_match=all&solved=false&search=sum&sort=acceptance&order=desc&page=2&page_size=10
+func parseProblemListQuery(r *http.Request) (models.ProblemListQuery, error) {
+	q := r.URL.Query()
+	query := models.ProblemListQuery{
+		Difficulty: strings.TrimSpace(q.Get("difficulty")),
+		Search:     strings.TrimSpace(q.Get("search")),
+		SortBy:     "id",
+		Page:       1,
+		PageSize:   defaultProblemPageSize,
+	}
+
+	for _, tag := range strings.Split(q.Get("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			query.Tags = append(query.Tags, tag)
+		}
+	}
+
+	switch q.Get("tag_match") {
+	case "", "any":
+	case "all":
+		query.MatchAllTag = true
+	default:
+		return query, errors.New("tag_match must be one of: any, all")
+	}
+
+	if v := q.Get("solved"); v != "" {
+		solved, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, errors.New("solved must be true or false")
+		}
+		query.Solved = &solved
+	}
+
+	switch v := q.Get("sort"); v {
+	case "":
+	case "id", "acceptance", "difficulty":
+		query.SortBy = v
+	default:
+		return query, errors.New("sort must be one of: id, acceptance, difficulty")
+	}
+
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, errors.New("order must be one of: asc, desc")
+	}
+
+	if v := q.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return query, errors.New("page must be a positive integer")
+		}
+		query.Page = page
+	}
+
+	if v := q.Get("page_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 || size > maxProblemPageSize {
+			return query, fmt.Errorf("page_size must be between 1 and %d", maxProblemPageSize)
+		}
+		query.PageSize = size
+	}
+
+	return query, nil
+}
+
+func (s *problemService) GetProblems(w http.ResponseWriter, r *http.Request) {
+	userID, ok := withUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query, err := parseProblemListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	problems, err := s.repo.GetProblems(r.Context(), userID, query)
+	if err != nil {
+		log.Println("Failed to fetch problems: ", err)
+		http.Error(w, "Failed to fetch problems", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, problems)
+}
+
+// SearchProblems handles GET /problems/search?q=shortest+path&page=1&page_size=20
+func (s *problemService) SearchProblems(w http.ResponseWriter, r *http.Request) {
+	userID, ok := withUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Reuse the pagination rules of the problem list.
+	query, err := parseProblemListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	text := strings.TrimSpace(r.URL.Query().Get("q"))
+	if text == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.repo.SearchProblems(r.Context(), userID, text, query.Page, query.PageSize)
+	if err != nil {
+		log.Println("Failed to search problems: ", err)
+		http.Error(w, "Failed to search problems", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *problemService) ViewProblem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := withUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	problemIDStr := chi.URLParam(r, "problemId")
+	problemID, err := strconv.Atoi(problemIDStr)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+
+	problem, err := s.repo.ViewProblem(r.Context(), userID, problemID)
+	if err != nil {
+		log.Println("Failed to fetch problem: ", err)
+		http.Error(w, "Failed to fetch problem", http.StatusInternalServerError)
+		return
+	}
+	if problem == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, problem)
+}
@@ -1,78 +1,99 @@
-package main
-
-import (
-	"context"
-	"fmt"
-	"log"
-	"net/http"
-	authmodule "online-judge/internal/auth_module"
-	"online-judge/internal/config"
-	"online-judge/internal/handlers"
-	"online-judge/internal/models"
-	"online-judge/internal/repo"
-	"online-judge/internal/router"
-	"online-judge/internal/server"
-	"online-judge/internal/services"
-	"os"
-	"sync"
-	"time"
-)
-
-func main() {
-	if os.Getenv("PORT") != "PRODUCTION" {
-		_ = config.LoadEnv(".env")
-	}
-	cfg, err := config.NewConfig()
-	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
-	}
-
-	auth := authmodule.NewJWTAuth([]byte(cfg.JWT_SECRET), time.Minute*time.Duration(cfg.TOKEN_EXPIRY_MINUTES))
-
-	redisClient := services.NewRedisService(cfg.REDIS_ADDR)
-	defer redisClient.Close()
-
-	ctx, cancel := context.WithCancel(context.TODO())
-	defer cancel()
-
-	var wg sync.WaitGroup
-
-	problemRepo := repo.NewProblemRepo()
-	submissionRepo := repo.NewSubmissionRepo()
-
-	redisClient.StartResultWorker(ctx, func(ecr *models.ExecuteCodeResponse) {
-		status, runtime, memory := "Accepted", 0, 0
-		for i, v := range ecr.TestCaseResults {
-			if v.RuntimeMS > runtime {
-				runtime = v.RuntimeMS
-			}
-			if v.MemoryKB > memory {
-				memory = v.MemoryKB
-			}
-			if v.Status != "Accepted" {
-				status = fmt.Sprintf("%s on Test Case : %d", v.Status, i+1)
-			}
-		}
-		log.Println("Result received: ", status, runtime, memory)
-		if ecr.ExecutionType == "submission" {
-			submissionRepo.UpdateSubmission(ctx, ecr.ID, runtime, memory, status)
-		} else if ecr.ExecutionType == "validation" {
-			problemRepo.UpdateProblemStatusByID(ctx, ecr.ID, status)
-		}
-	}, &wg)
-
-	handler, err := handlers.NewHandler(submissionRepo, problemRepo, redisClient)
-	if err != nil {
-		log.Fatalf("Failed to load handler: %v", err)
-	}
-
-	r := router.NewChiRouter(nil, auth, *handler)
-
-	s := http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.SERVER_PORT),
-		Handler: r,
-	}
-
-	server.RunWithGracefulShutdown(&s)
-	wg.Wait()
-}
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	authmodule "online-judge/internal/auth_module"
+	"online-judge/internal/config"
+	"online-judge/internal/handlers"
+	"online-judge/internal/models"
+	"online-judge/internal/realtime"
+	"online-judge/internal/repo"
+	"online-judge/internal/router"
+	"online-judge/internal/server"
+	"online-judge/internal/services"
+	"online-judge/internal/webhooks"
+	"os"
+	"sync"
+	"time"
+)
+
+func main() {
+	if os.Getenv("PORT") != "PRODUCTION" {
+		_ = config.LoadEnv(".env")
+	}
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	auth := authmodule.NewJWTAuth([]byte(cfg.JWT_SECRET), time.Minute*time.Duration(cfg.TOKEN_EXPIRY_MINUTES))
+
+	redisClient := services.NewRedisService(cfg.REDIS_ADDR)
+	defer redisClient.Close()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	problemRepo := repo.NewProblemRepo()
+	submissionRepo := repo.NewSubmissionRepo()
+	runRepo := repo.NewRunRepo()
+	rejudgeRepo := repo.NewRejudgeRepo()
+	contestRepo := repo.NewContestRepo()
+	userRepo := repo.NewUserRepo()
+	if cfg.ADMIN_PASSWORD != "" {
+		hash, err := authmodule.HashPassword(cfg.ADMIN_PASSWORD)
+		if err == nil {
+			err = userRepo.SetPassword(ctx, 1, hash)
+		}
+		if err != nil {
+			log.Fatalf("Failed to set the admin password: %v", err)
+		}
+	}
+	webhookRepo := repo.NewWebhookRepo()
+	tokenRepo := repo.NewTokenRepo()
+	hub := realtime.NewHub()
+	dispatcher := webhooks.NewDispatcher(webhookRepo)
+
+	queueLimits := services.QueueLimits{
+		SubmitRatePerMinute: cfg.SUBMIT_RATE_PER_MINUTE,
+		SubmitBurst:         cfg.SUBMIT_BURST,
+		MaxPendingPerUser:   cfg.MAX_PENDING_SUBMISSIONS,
+		MaxQueueDepth:       cfg.MAX_QUEUE_DEPTH,
+		MaxQueueWait:        time.Duration(cfg.MAX_QUEUE_WAIT_SECONDS) * time.Second,
+	}
+
+	timeLimits := services.TimeLimits{
+		Factor:      cfg.TIME_LIMIT_FACTOR,
+		Auto:        cfg.AUTO_TIME_LIMITS,
+		Multipliers: cfg.LANGUAGE_TIME_MULTIPLIERS,
+	}
+
+	handler, err := handlers.NewHandler(submissionRepo, problemRepo, runRepo, rejudgeRepo, contestRepo, userRepo, webhookRepo, tokenRepo, hub, dispatcher, redisClient, queueLimits, timeLimits, auth)
+	if err != nil {
+		log.Fatalf("Failed to load handler: %v", err)
+	}
+
+	dispatcher.Start(ctx, &wg)
+	redisClient.StartResultWorker(ctx, func(ecr *models.ExecuteCodeResponse) {
+		handler.HandleExecutionResult(ctx, ecr)
+	}, &wg)
+	redisClient.StartStatusWorker(ctx, func(event *models.ExecutionEvent) {
+		handler.HandleExecutionEvent(ctx, event)
+	}, &wg)
+	redisClient.StartVerdictSubscriber(ctx, hub.Deliver, &wg)
+
+	r := router.NewChiRouter(nil, auth, *handler)
+
+	s := http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.SERVER_PORT),
+		Handler: r,
+	}
+
+	server.RunWithGracefulShutdown(&s)
+	wg.Wait()
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"online-judge/internal/models"
+
+	"github.com/gorilla/websocket"
+)
+
+// client calls the judge API with a personal access token.
+type client struct {
+	server string
+	token  string
+	http   *http.Client
+}
+
+func newClient(cfg cliConfig) *client {
+	return &client{
+		server: strings.TrimRight(cfg.Server, "/"),
+		token:  cfg.Token,
+		http:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// apiError is a non-2xx response. The API replies with plain-text messages.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return http.StatusText(e.StatusCode)
+	}
+	return e.Message
+}
+
+func (c *client) do(ctx context.Context, method, path string, body interface{}, header http.Header, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.server+path, reader)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		apiErr := &apiError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+		if retry := resp.Header.Get("Retry-After"); retry != "" && resp.StatusCode == http.StatusTooManyRequests {
+			apiErr.Message += " (retry after " + retry + "s)"
+		}
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *client) listProblems(ctx context.Context, query url.Values) (*models.ProblemListPage, error) {
+	var page models.ProblemListPage
+	err := c.do(ctx, http.MethodGet, "/api/problems?"+query.Encode(), nil, nil, &page)
+	return &page, err
+}
+
+func (c *client) getProblem(ctx context.Context, id int) (*models.ProblemDetail, error) {
+	var problem models.ProblemDetail
+	err := c.do(ctx, http.MethodGet, "/api/problems/"+strconv.Itoa(id), nil, nil, &problem)
+	return &problem, err
+}
+
+// resolveProblem accepts a problem ID or slug. Slugs are looked up by walking the problem
+// list, since the API addresses problems by ID.
+func (c *client) resolveProblem(ctx context.Context, ref string) (int, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return id, nil
+	}
+	query := url.Values{"page_size": {"100"}}
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		list, err := c.listProblems(ctx, query)
+		if err != nil {
+			return 0, err
+		}
+		for _, p := range list.Problems {
+			if strings.EqualFold(p.Slug, ref) {
+				return p.ID, nil
+			}
+		}
+		if page >= list.TotalPages {
+			return 0, fmt.Errorf("no problem with slug %q", ref)
+		}
+	}
+}
+
+func (c *client) run(ctx context.Context, problemID int, payload models.RunCodePayload) (int, error) {
+	var resp models.RunCodeResponse
+	err := c.post(ctx, fmt.Sprintf("/api/problems/%d/run", problemID), payload, &resp)
+	return resp.RunID, err
+}
+
+// waitForRun polls a run until it has been executed.
+func (c *client) waitForRun(ctx context.Context, runID int) (*models.RunDB, error) {
+	for {
+		var run models.RunDB
+		if err := c.do(ctx, http.MethodGet, "/api/runs/"+strconv.Itoa(runID), nil, nil, &run); err != nil {
+			return nil, err
+		}
+		if run.Status != "pending" {
+			return &run, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (c *client) submit(ctx context.Context, payload models.SubmitCodePayload) (*models.SubmitCodeResponse, error) {
+	var resp models.SubmitCodeResponse
+	err := c.post(ctx, "/api/submit", payload, &resp)
+	return &resp, err
+}
+
+// waitForVerdict follows a submission over the WebSocket and falls back to polling when
+// the socket cannot be used.
+func (c *client) waitForVerdict(ctx context.Context, submissionID int) (*models.VerdictEvent, error) {
+	event, err := c.streamVerdict(ctx, submissionID)
+	if err == nil {
+		return event, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	for {
+		var submission models.SubmissionDB
+		err := c.do(ctx, http.MethodGet, "/api/submissions/"+strconv.Itoa(submissionID), nil, nil, &submission)
+		if err == nil && submission.State.IsFinal() {
+			event := models.NewVerdictEvent(submission)
+			return &event, nil
+		}
+		// Pending submissions are reported as errors by GET /submissions/{id}.
+		var apiErr *apiError
+		if err != nil && (!errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+func (c *client) streamVerdict(ctx context.Context, submissionID int) (*models.VerdictEvent, error) {
+	wsURL := "ws" + strings.TrimPrefix(c.server, "http") + "/api/ws/submissions"
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	if err := conn.WriteJSON(map[string]interface{}{
+		"action":         "subscribe",
+		"submission_ids": []int{submissionID},
+	}); err != nil {
+		return nil, err
+	}
+	for {
+		var event models.VerdictEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			return nil, err
+		}
+		if event.SubmissionID == submissionID && event.State.IsFinal() {
+			return &event, nil
+		}
+	}
+}
+
+// postAttempts is how many times post sends a request before giving up.
+const postAttempts = 3
+
+// post sends a request that queues work, retrying network errors and 5xx responses.
+// Every attempt carries the same Idempotency-Key, so the server queues the work once.
+func (c *client) post(ctx context.Context, path string, body, out interface{}) error {
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return err
+	}
+	header := http.Header{"Idempotency-Key": {key}}
+
+	for attempt := 1; ; attempt++ {
+		err = c.do(ctx, http.MethodPost, path, body, header, out)
+		var apiErr *apiError
+		if err == nil || attempt == postAttempts || (errors.As(err, &apiErr) && apiErr.StatusCode < 500) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * time.Second):
+		}
+	}
+}
+
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating an idempotency key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
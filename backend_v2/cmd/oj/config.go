@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const defaultServer = "http://localhost:8080"
+
+// cliConfig is saved by "oj login" and read by every other command.
+type cliConfig struct {
+	Server string `json:"server"`
+	Token  string `json:"token"`
+}
+
+func configPath() (string, error) {
+	if path := os.Getenv("OJ_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "oj", "config.json"), nil
+}
+
+// loadConfig reads the saved config. OJ_SERVER and OJ_TOKEN override it, so the CLI can
+// run in CI without logging in.
+func loadConfig() (cliConfig, error) {
+	cfg := cliConfig{Server: defaultServer}
+
+	path, err := configPath()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return cfg, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	if server := os.Getenv("OJ_SERVER"); server != "" {
+		cfg.Server = server
+	}
+	if token := os.Getenv("OJ_TOKEN"); token != "" {
+		cfg.Token = token
+	}
+	return cfg, nil
+}
+
+// saveConfig writes the config readable by the current user only, as it holds a token.
+func saveConfig(cfg cliConfig) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,328 @@
+// Command oj is a terminal client for the judge.
+//
+//	oj login [-server URL] [-token TOKEN]
+//	oj problems [-difficulty D] [-tags a,b] [-search TEXT] [-page N]
+//	oj problem [-samples DIR] PROBLEM
+//	oj test PROBLEM FILE
+//	oj submit [-contest ID] PROBLEM FILE
+//
+// PROBLEM is a problem ID or slug. The language is inferred from the file extension.
+// Authentication uses a personal access token created with POST /api/tokens.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	mockdata "online-judge/internal/mock_data"
+	"online-judge/internal/models"
+)
+
+const usage = `usage: oj <command> [arguments]
+
+commands:
+  login     save the server URL and a personal access token
+  problems  list problems
+  problem   show a problem with its samples
+  test      run a file against the samples of a problem
+  submit    submit a file and wait for the verdict
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "login":
+		err = login(ctx, args)
+	case "problems":
+		err = listProblems(ctx, args)
+	case "problem":
+		err = showProblem(ctx, args)
+	case "test":
+		err = testFile(ctx, args)
+	case "submit":
+		err = submitFile(ctx, args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "oj: unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "oj:", err)
+		os.Exit(1)
+	}
+}
+
+func login(ctx context.Context, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	server := fs.String("server", cfg.Server, "judge URL")
+	token := fs.String("token", "", "personal access token (read from stdin when omitted)")
+	fs.Parse(args)
+
+	if *token == "" {
+		fmt.Fprint(os.Stderr, "Personal access token: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return err
+		}
+		*token = strings.TrimSpace(line)
+	}
+	if *token == "" {
+		return errors.New("a token is required")
+	}
+
+	cfg = cliConfig{Server: strings.TrimRight(*server, "/"), Token: *token}
+	if _, err := newClient(cfg).listProblems(ctx, url.Values{"page_size": {"1"}}); err != nil {
+		return fmt.Errorf("could not log in: %w", err)
+	}
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+	fmt.Println("Logged in to", cfg.Server)
+	return nil
+}
+
+func listProblems(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("problems", flag.ExitOnError)
+	difficulty := fs.String("difficulty", "", "easy, medium or hard")
+	tags := fs.String("tags", "", "comma-separated tags")
+	search := fs.String("search", "", "text in the title")
+	page := fs.Int("page", 1, "page number")
+	fs.Parse(args)
+
+	c, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{"page": {strconv.Itoa(*page)}}
+	if *difficulty != "" {
+		query.Set("difficulty", *difficulty)
+	}
+	if *tags != "" {
+		query.Set("tags", *tags)
+	}
+	if *search != "" {
+		query.Set("search", *search)
+	}
+	list, err := c.listProblems(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSLUG\tTITLE\tDIFFICULTY\tACCEPTANCE\tSOLVED")
+	for _, p := range list.Problems {
+		solved := ""
+		if p.IsSolved {
+			solved = "yes"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%.1f%%\t%s\n", p.ID, p.Slug, p.Title, p.Difficulty.Name, p.AcceptanceRate, solved)
+	}
+	tw.Flush()
+	fmt.Printf("page %d of %d, %d problems\n", list.Page, list.TotalPages, list.Total)
+	return nil
+}
+
+func showProblem(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("problem", flag.ExitOnError)
+	samples := fs.String("samples", "", "directory to write the samples to as N.in and N.out")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("usage: oj problem [-samples DIR] PROBLEM")
+	}
+
+	c, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+	id, err := c.resolveProblem(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	p, err := c.getProblem(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d. %s (%s)\n\n%s\n", p.ID, p.Title, p.Difficulty.Name, p.Description)
+	if len(p.Constraints) > 0 {
+		fmt.Println("\nConstraints:")
+		for _, line := range p.Constraints {
+			fmt.Println("  -", line)
+		}
+	}
+	for i, ex := range p.Examples {
+		fmt.Printf("\nExample %d\n  Input:\n%s  Output:\n%s", i+1, indent(ex.Input), indent(ex.ExpectedOutput))
+		if ex.Explanation != "" {
+			fmt.Printf("  Explanation: %s\n", ex.Explanation)
+		}
+	}
+
+	if *samples == "" {
+		return nil
+	}
+	if err := os.MkdirAll(*samples, 0o755); err != nil {
+		return err
+	}
+	for i, ex := range p.Examples {
+		base := filepath.Join(*samples, strconv.Itoa(i+1))
+		if err := os.WriteFile(base+".in", []byte(ex.Input+"\n"), 0o644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(base+".out", []byte(ex.ExpectedOutput+"\n"), 0o644); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("\nWrote %d samples to %s\n", len(p.Examples), *samples)
+	return nil
+}
+
+func testFile(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: oj test PROBLEM FILE")
+	}
+	c, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+	id, languageID, code, err := prepare(ctx, c, args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	runID, err := c.run(ctx, id, models.RunCodePayload{LanguageID: languageID, Code: code})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Running against the samples (run %d)...\n", runID)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	run, err := c.waitForRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+
+	passed := 0
+	for i, res := range run.TestCaseResults {
+		if res.Status == "Accepted" {
+			passed++
+			fmt.Printf("  test %d: %s (%d ms, %d KB)\n", i+1, res.Status, res.RuntimeMS, res.MemoryKB)
+			continue
+		}
+		fmt.Printf("  test %d: %s\n    input:    %s\n    expected: %s\n    output:   %s\n",
+			i+1, res.Status, res.Input, res.ExpectedOutput, res.Output)
+	}
+	if passed != len(run.TestCaseResults) {
+		return fmt.Errorf("%d/%d samples passed", passed, len(run.TestCaseResults))
+	}
+	fmt.Printf("All %d samples passed\n", passed)
+	return nil
+}
+
+func submitFile(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("submit", flag.ExitOnError)
+	contestID := fs.Int("contest", 0, "contest ID, for problems of a running contest")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return errors.New("usage: oj submit [-contest ID] PROBLEM FILE")
+	}
+
+	c, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+	id, languageID, code, err := prepare(ctx, c, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.submit(ctx, models.SubmitCodePayload{
+		ProblemID:  id,
+		LanguageID: languageID,
+		Code:       code,
+		ContestID:  *contestID,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Submitted %d: position %d in the queue, about %ds until judging starts\n",
+		resp.SubmissionID, resp.QueuePosition, resp.EstimatedWaitSeconds)
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Minute)
+	defer cancel()
+	event, err := c.waitForVerdict(ctx, resp.SubmissionID)
+	if err != nil {
+		return err
+	}
+
+	if event.Verdict != "Accepted" {
+		return errors.New(event.Status)
+	}
+	fmt.Printf("Accepted: runtime %d ms, memory %d KB\n", event.RuntimeMS, event.MemoryKB)
+	return nil
+}
+
+func authenticatedClient() (*client, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("not logged in, run: oj login")
+	}
+	return newClient(cfg), nil
+}
+
+// prepare resolves the problem and reads the source file with its language.
+func prepare(ctx context.Context, c *client, problem, file string) (problemID, languageID int, code string, err error) {
+	languageID, ok := mockdata.LanguageByExtension(filepath.Ext(file))
+	if !ok {
+		var extensions []string
+		for _, lang := range mockdata.ProgrammingLanguages {
+			extensions = append(extensions, mockdata.LanguageExtensions[lang.ID])
+		}
+		return 0, 0, "", fmt.Errorf("cannot tell the language of %s, expected one of %s", file, strings.Join(extensions, ", "))
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	problemID, err = c.resolveProblem(ctx, problem)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	return problemID, languageID, string(data), nil
+}
+
+func indent(text string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		b.WriteString("    " + line + "\n")
+	}
+	return b.String()
+}
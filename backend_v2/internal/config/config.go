@@ -1,71 +1,168 @@
-package config
-
-import (
-	"fmt"
-	"os"
-	"strconv"
-
-	"github.com/joho/godotenv"
-)
-
-type Config struct {
-	SERVER_PORT          int
-	DB_URI               string
-	JWT_SECRET           string
-	TOKEN_EXPIRY_MINUTES int
-	REDIS_ADDR           string
-}
-
-// LoadEnv attempts to load .env file from the given path.
-func LoadEnv(encPath string) error {
-	if encPath == "" {
-		encPath = ".env"
-	}
-	if err := godotenv.Load(encPath); err != nil {
-		fmt.Println("Warning: No .env file found or failed to load:", err)
-	}
-	return nil
-}
-
-// NewConfig initializes config from environment variables with validation.
-func NewConfig() (*Config, error) {
-	portStr := os.Getenv("PORT")
-	if portStr == "" {
-		portStr = "8080" // default fallback
-	}
-	port, err := strconv.Atoi(portStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid PORT value: %w", err)
-	}
-
-	dbURI := os.Getenv("DB_DSN")
-	if dbURI == "" {
-		return nil, fmt.Errorf("DB_DSN is required")
-	}
-
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		return nil, fmt.Errorf("JWT_SECRET is required")
-	}
-
-	tokenExpiryMinutesStr := os.Getenv("PORT")
-	if tokenExpiryMinutesStr == "" {
-		tokenExpiryMinutesStr = "8080" // default fallback
-	}
-	tokenExpiryMinutes, err := strconv.Atoi(tokenExpiryMinutesStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid TOKEN_EXPIRY_MINUTES value: %w", err)
-	}
-
-	redisAddr := os.Getenv("REDIS_ADDR")
-	if redisAddr == "" {
-		redisAddr = "localhost:6379"
-	}
-
-	return &Config{
-		SERVER_PORT:          port,
-		DB_URI:               dbURI,
-		JWT_SECRET:           jwtSecret,
-		TOKEN_EXPIRY_MINUTES: tokenExpiryMinutes,
-	}, nil
-}
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+type Config struct {
+	SERVER_PORT          int
+	DB_URI               string
+	JWT_SECRET           string
+	TOKEN_EXPIRY_MINUTES int
+	REDIS_ADDR           string
+	ADMIN_PASSWORD       string // password of the default administrator; it cannot log in when empty
+
+	SUBMIT_RATE_PER_MINUTE  int // tokens added to a user's submission bucket per minute
+	SUBMIT_BURST            int // size of a user's submission bucket
+	MAX_PENDING_SUBMISSIONS int // submissions a user may have waiting for a verdict
+	MAX_QUEUE_DEPTH         int // queued jobs above which submissions are rejected with 429
+	MAX_QUEUE_WAIT_SECONDS  int // estimated wait above which submissions are rejected with 429
+
+	TIME_LIMIT_FACTOR         float64            // time limit as a multiple of the reference solution's worst time
+	AUTO_TIME_LIMITS          bool               // set calibrated time limits instead of only suggesting them
+	LANGUAGE_TIME_MULTIPLIERS map[string]float64 // lower-case language name -> multiplier of the base time limit
+}
+
+// LoadEnv attempts to load .env file from the given path.
+func LoadEnv(encPath string) error {
+	if encPath == "" {
+		encPath = ".env"
+	}
+	if err := godotenv.Load(encPath); err != nil {
+		fmt.Println("Warning: No .env file found or failed to load:", err)
+	}
+	return nil
+}
+
+// NewConfig initializes config from environment variables with validation.
+func NewConfig() (*Config, error) {
+	portStr := os.Getenv("PORT")
+	if portStr == "" {
+		portStr = "8080" // default fallback
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PORT value: %w", err)
+	}
+
+	dbURI := os.Getenv("DB_DSN")
+	if dbURI == "" {
+		return nil, fmt.Errorf("DB_DSN is required")
+	}
+
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		return nil, fmt.Errorf("JWT_SECRET is required")
+	}
+
+	tokenExpiryMinutes, err := positiveIntEnv("TOKEN_EXPIRY_MINUTES", 24*60)
+	if err != nil {
+		return nil, err
+	}
+
+	redisAddr := os.Getenv("REDIS_ADDR")
+	if redisAddr == "" {
+		redisAddr = "localhost:6379"
+	}
+
+	submitRate, err := positiveIntEnv("SUBMIT_RATE_PER_MINUTE", 6)
+	if err != nil {
+		return nil, err
+	}
+	submitBurst, err := positiveIntEnv("SUBMIT_BURST", 10)
+	if err != nil {
+		return nil, err
+	}
+	maxPending, err := positiveIntEnv("MAX_PENDING_SUBMISSIONS", 5)
+	if err != nil {
+		return nil, err
+	}
+
+	maxQueueDepth, err := positiveIntEnv("MAX_QUEUE_DEPTH", 500)
+	if err != nil {
+		return nil, err
+	}
+	maxQueueWait, err := positiveIntEnv("MAX_QUEUE_WAIT_SECONDS", 300)
+	if err != nil {
+		return nil, err
+	}
+
+	timeLimitFactor, err := positiveFloatEnv("TIME_LIMIT_FACTOR", 2)
+	if err != nil {
+		return nil, err
+	}
+	autoTimeLimits := os.Getenv("AUTO_TIME_LIMITS") == "true"
+	multipliers, err := languageMultipliersEnv("LANGUAGE_TIME_MULTIPLIERS", "go=1,javascript=2,python=3")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		SERVER_PORT:          port,
+		DB_URI:               dbURI,
+		JWT_SECRET:           jwtSecret,
+		TOKEN_EXPIRY_MINUTES: tokenExpiryMinutes,
+		REDIS_ADDR:           redisAddr,
+		ADMIN_PASSWORD:       os.Getenv("ADMIN_PASSWORD"),
+
+		SUBMIT_RATE_PER_MINUTE:  submitRate,
+		SUBMIT_BURST:            submitBurst,
+		MAX_PENDING_SUBMISSIONS: maxPending,
+		MAX_QUEUE_DEPTH:         maxQueueDepth,
+		MAX_QUEUE_WAIT_SECONDS:  maxQueueWait,
+
+		TIME_LIMIT_FACTOR:         timeLimitFactor,
+		AUTO_TIME_LIMITS:          autoTimeLimits,
+		LANGUAGE_TIME_MULTIPLIERS: multipliers,
+	}, nil
+}
+
+// positiveIntEnv reads an optional positive integer, falling back to def when unset.
+func positiveIntEnv(name string, def int) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid %s value %q: must be a positive integer", name, v)
+	}
+	return n, nil
+}
+
+// positiveFloatEnv reads an optional positive number, falling back to def when unset.
+func positiveFloatEnv(name string, def float64) (float64, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f <= 0 {
+		return 0, fmt.Errorf("invalid %s value %q: must be a positive number", name, v)
+	}
+	return f, nil
+}
+
+// languageMultipliersEnv reads "language=multiplier" pairs separated by commas, such as
+// "go=1,python=3", falling back to def when unset.
+func languageMultipliersEnv(name, def string) (map[string]float64, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		v = def
+	}
+	multipliers := make(map[string]float64)
+	for _, pair := range strings.Split(v, ",") {
+		language, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || err != nil || f <= 0 || strings.TrimSpace(language) == "" {
+			return nil, fmt.Errorf("invalid %s entry %q: want language=multiplier", name, pair)
+		}
+		multipliers[strings.ToLower(strings.TrimSpace(language))] = f
+	}
+	return multipliers, nil
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"online-judge/internal/services"
+)
+
+const (
+	// capacityWindow is how far back finished submissions are used to estimate judge speed.
+	capacityWindow = 10 * time.Minute
+	// defaultJudgeDuration is assumed per submission until the judge has finished some.
+	defaultJudgeDuration = 2 * time.Second
+)
+
+// estimateWait estimates how long it takes the judge to work through jobsAhead jobs, from
+// the average judging time and the number of workers seen recently.
+func (h *Handler) estimateWait(ctx context.Context, jobsAhead int) time.Duration {
+	avg, workers, err := h.submissionRepo.GetJudgeCapacity(ctx, capacityWindow)
+	if err != nil || avg == 0 {
+		avg = defaultJudgeDuration
+	}
+	return time.Duration(jobsAhead) * avg / time.Duration(max(workers, 1))
+}
+
+// jobsAhead counts the jobs judged before a submission at position in the submit lane.
+// Runs are served before submissions, so as in queueOverload the run lane counts as ahead.
+func (h *Handler) jobsAhead(ctx context.Context, language string, position int) int {
+	ahead := position - 1
+	if depths, err := h.redisService.QueueDepths(ctx, language); err == nil {
+		ahead += int(depths[services.LaneRun])
+	}
+	return ahead
+}
+
+// queueOverload reports whether a new submission must be rejected because the queue is too
+// deep or too slow, and if so after how long the client should retry. Runs are served
+// before submissions, so both lanes count as ahead of it.
+func (h *Handler) queueOverload(ctx context.Context, language string) (bool, time.Duration, error) {
+	depths, err := h.redisService.QueueDepths(ctx, language)
+	if err != nil {
+		return false, 0, err
+	}
+	ahead := int(depths[services.LaneRun] + depths[services.LaneSubmit])
+	wait := h.estimateWait(ctx, ahead)
+
+	limits := h.queueLimits
+	if ahead < limits.MaxQueueDepth && wait <= limits.MaxQueueWait {
+		return false, 0, nil
+	}
+
+	// Retry once the queue has drained back below both thresholds.
+	retryAfter := max(wait-limits.MaxQueueWait, h.estimateWait(ctx, ahead-limits.MaxQueueDepth+1), time.Second)
+	return true, retryAfter, nil
+}
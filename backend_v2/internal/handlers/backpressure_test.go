@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"online-judge/internal/models"
+	"online-judge/internal/repo"
+	"online-judge/internal/services"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestEstimateWait(t *testing.T) {
+	tests := []struct {
+		name      string
+		workers   []string // worker of each submission judged in 4 s
+		jobsAhead int
+		want      time.Duration
+	}{
+		{name: "empty queue", workers: []string{"w1"}},
+		{name: "no data", jobsAhead: 3, want: 3 * defaultJudgeDuration},
+		{name: "one worker", workers: []string{"w1", "w1"}, jobsAhead: 3, want: 12 * time.Second},
+		{name: "two workers", workers: []string{"w1", "w2"}, jobsAhead: 3, want: 6 * time.Second},
+		{name: "unknown worker", workers: []string{""}, jobsAhead: 3, want: 12 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{submissionRepo: repo.NewSubmissionRepo()}
+			ctx := context.Background()
+			for _, worker := range tt.workers {
+				id, _ := h.submissionRepo.NewSubmission(ctx, models.SubmissionDB{UserID: 2}, 0)
+				finished := time.Now()
+				if err := h.submissionRepo.UpdateSubmission(ctx, id, models.SubmissionJudgement{
+					State:      models.SubmissionStateJudged,
+					WorkerID:   worker,
+					StartedAt:  finished.Add(-4 * time.Second),
+					FinishedAt: finished,
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := h.estimateWait(ctx, tt.jobsAhead); got != tt.want {
+				t.Errorf("estimateWait(%d) = %v, want %v", tt.jobsAhead, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueueOverload(t *testing.T) {
+	tests := []struct {
+		name           string
+		runs, submits  int
+		maxDepth       int
+		maxWait        time.Duration
+		want           bool
+		wantRetryAfter time.Duration
+	}{
+		{name: "empty", maxDepth: 5, maxWait: time.Minute},
+		{name: "below both limits", runs: 2, submits: 2, maxDepth: 5, maxWait: time.Minute},
+		{name: "too deep", runs: 3, submits: 3, maxDepth: 5, maxWait: time.Minute, want: true, wantRetryAfter: 2 * defaultJudgeDuration},
+		{name: "too slow", submits: 4, maxDepth: 100, maxWait: 5 * time.Second, want: true, wantRetryAfter: 3 * time.Second},
+		{name: "at the depth limit", submits: 5, maxDepth: 5, maxWait: time.Minute, want: true, wantRetryAfter: defaultJudgeDuration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			redisService := services.NewRedisService(mr.Addr())
+			defer redisService.Close()
+			h := &Handler{
+				submissionRepo: repo.NewSubmissionRepo(),
+				redisService:   redisService,
+				queueLimits:    services.QueueLimits{MaxQueueDepth: tt.maxDepth, MaxQueueWait: tt.maxWait},
+			}
+			ctx := context.Background()
+			for i := 0; i < tt.runs+tt.submits; i++ {
+				executionType := models.ExecutionTypeSubmission
+				if i < tt.runs {
+					executionType = models.ExecutionTypeRun
+				}
+				if err := redisService.ExecuteCode(ctx, "python", models.ExecuteCodePayload{
+					ID: i + 1, UserID: i + 1, ExecutionType: executionType,
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			overloaded, retryAfter, err := h.queueOverload(ctx, "python")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if overloaded != tt.want || retryAfter != tt.wantRetryAfter {
+				t.Errorf("queueOverload = %v, %v, want %v, %v", overloaded, retryAfter, tt.want, tt.wantRetryAfter)
+			}
+		})
+	}
+}
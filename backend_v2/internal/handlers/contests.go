@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const maxContestProblems = 26
+
+var contestLabelPattern = regexp.MustCompile(`^[A-Z][0-9]?$`)
+
+func contestIDFromURL(r *http.Request) (int, error) {
+	return strconv.Atoi(chi.URLParam(r, "contestID"))
+}
+
+// buildContest validates a contest payload and resolves its problems. Missing labels are
+// assigned A, B, C... in order. Only active problems can be part of a contest.
+func (h *Handler) buildContest(ctx context.Context, payload models.ContestPayload) (models.ContestDB, error) {
+	contest := models.ContestDB{
+		Title:       strings.TrimSpace(payload.Title),
+		Description: payload.Description,
+		StartTime:   payload.StartTime,
+		EndTime:     payload.EndTime,
+	}
+	if contest.Title == "" {
+		return contest, errors.New("title is required")
+	}
+	if contest.StartTime.IsZero() || !contest.StartTime.Before(contest.EndTime) {
+		return contest, errors.New("start_time must be before end_time")
+	}
+	if len(payload.Problems) == 0 || len(payload.Problems) > maxContestProblems {
+		return contest, fmt.Errorf("a contest needs between 1 and %d problems", maxContestProblems)
+	}
+
+	labels := make(map[string]bool)
+	problems := make(map[int]bool)
+	for i, cp := range payload.Problems {
+		if cp.Label == "" {
+			cp.Label = string(rune('A' + i))
+		}
+		cp.Label = strings.ToUpper(cp.Label)
+		if !contestLabelPattern.MatchString(cp.Label) {
+			return contest, fmt.Errorf("invalid label %q, expected a letter such as A or B1", cp.Label)
+		}
+		if labels[cp.Label] {
+			return contest, fmt.Errorf("label %s is used twice", cp.Label)
+		}
+		if problems[cp.ProblemID] {
+			return contest, fmt.Errorf("problem %d is used twice", cp.ProblemID)
+		}
+		labels[cp.Label], problems[cp.ProblemID] = true, true
+
+		// Problems awaiting review are not public yet, so they can be kept secret until
+		// the contest starts.
+		problem, err := h.problemRepo.GetProblemMetadata(ctx, cp.ProblemID, false)
+		if err != nil {
+			return contest, fmt.Errorf("problem %d: %w", cp.ProblemID, err)
+		}
+		if problem.Status != models.ProblemStatusActive && problem.Status != models.ProblemStatusAwaitingReview {
+			return contest, fmt.Errorf("problem %d is %s; contest problems must be active or awaiting review", cp.ProblemID, problem.Status)
+		}
+		cp.Title = problem.Title
+		contest.Problems = append(contest.Problems, cp)
+	}
+	return contest, nil
+}
+
+func (h *Handler) CreateContest(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	var payload models.ContestPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contest, err := h.buildContest(r.Context(), payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	contest.CreatedBy = userID
+
+	id, err := h.contestRepo.CreateContest(r.Context(), contest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"contest_id": id})
+}
+
+// UpdateContest replaces a contest's details. The problem set and start time are frozen
+// once it started, and the end time once it ended.
+func (h *Handler) UpdateContest(w http.ResponseWriter, r *http.Request) {
+	id, err := contestIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid contest ID", http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.contestRepo.GetContest(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var payload models.ContestPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contest, err := h.buildContest(r.Context(), payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	phase := existing.Phase(time.Now())
+	if phase != models.ContestPhaseUpcoming && !sameContestProblems(existing.Problems, contest.Problems) {
+		http.Error(w, "the problem set cannot change once the contest has started", http.StatusConflict)
+		return
+	}
+	if phase != models.ContestPhaseUpcoming && !contest.StartTime.Equal(existing.StartTime) {
+		http.Error(w, "the start time cannot change once the contest has started", http.StatusConflict)
+		return
+	}
+	if phase == models.ContestPhaseEnded && !contest.EndTime.Equal(existing.EndTime) {
+		http.Error(w, "the end time cannot change once the contest has ended", http.StatusConflict)
+		return
+	}
+	contest.ID = id
+
+	if err := h.contestRepo.UpdateContest(r.Context(), contest); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+func sameContestProblems(a, b []models.ContestProblem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Label != b[i].Label || a[i].ProblemID != b[i].ProblemID {
+			return false
+		}
+	}
+	return true
+}
+
+// contestInfo returns a contest as seen by a user. The problem set stays secret to
+// non-admins until the contest starts.
+func (h *Handler) contestInfo(ctx context.Context, contest models.ContestDB, userID int, isAdmin bool) models.ContestInfo {
+	info := models.ContestInfo{
+		ContestDB:    contest,
+		Phase:        contest.Phase(time.Now()),
+		Participants: h.contestRepo.CountParticipants(ctx, contest.ID),
+		IsRegistered: h.contestRepo.IsRegistered(ctx, contest.ID, userID),
+	}
+	if info.Phase == models.ContestPhaseUpcoming && !isAdmin {
+		info.Problems = []models.ContestProblem{}
+	}
+	return info
+}
+
+func (h *Handler) ListContests(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+	isAdmin, _ := r.Context().Value(middleware.IsAdminKey).(bool)
+
+	contests, err := h.contestRepo.ListContests(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	infos := make([]models.ContestInfo, 0, len(contests))
+	for _, c := range contests {
+		infos = append(infos, h.contestInfo(r.Context(), c, userID, isAdmin))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(infos)
+}
+
+func (h *Handler) GetContest(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+	isAdmin, _ := r.Context().Value(middleware.IsAdminKey).(bool)
+
+	id, err := contestIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid contest ID", http.StatusBadRequest)
+		return
+	}
+
+	contest, err := h.contestRepo.GetContest(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.contestInfo(r.Context(), *contest, userID, isAdmin))
+}
+
+func (h *Handler) RegisterForContest(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	id, err := contestIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid contest ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.contestRepo.Register(r.Context(), id, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// checkContestSubmission verifies that a user may submit a problem to a contest right now.
+// It returns the HTTP status to reply with when not.
+func (h *Handler) checkContestSubmission(ctx context.Context, contestID, problemID, userID int) (int, error) {
+	contest, err := h.contestRepo.GetContest(ctx, contestID)
+	if err != nil {
+		return http.StatusNotFound, err
+	}
+	if !containsContestProblem(contest.Problems, problemID) {
+		return http.StatusBadRequest, errors.New("problem is not part of this contest")
+	}
+	if contest.Phase(time.Now()) != models.ContestPhaseRunning {
+		return http.StatusForbidden, errors.New("contest is not running")
+	}
+	if !h.contestRepo.IsRegistered(ctx, contestID, userID) {
+		return http.StatusForbidden, errors.New("not registered for this contest")
+	}
+	return http.StatusOK, nil
+}
+
+func containsContestProblem(problems []models.ContestProblem, problemID int) bool {
+	for _, p := range problems {
+		if p.ProblemID == problemID {
+			return true
+		}
+	}
+	return false
+}
+
+// playableProblem returns a problem users may view, run and submit: an active one, or one
+// awaiting review that belongs to a contest which has started. Whether the user may access
+// the problems of a running contest is checked separately.
+func (h *Handler) playableProblem(ctx context.Context, problemID int) (*models.ProblemDB, error) {
+	problem, err := h.problemRepo.GetProblemMetadata(ctx, problemID, false)
+	if err != nil {
+		return nil, err
+	}
+	if problem.Status == models.ProblemStatusActive ||
+		problem.Status == models.ProblemStatusAwaitingReview && h.contestRepo.InStartedContest(ctx, problemID, time.Now()) {
+		return problem, nil
+	}
+	return nil, errors.New("active problem not found")
+}
+
+// canAccessProblem reports whether a user may view and run a problem that may belong to
+// a contest which has not ended yet.
+func (h *Handler) canAccessProblem(ctx context.Context, problemID, userID int, isAdmin bool) bool {
+	return isAdmin || h.contestRepo.CanAccessProblem(ctx, problemID, userID, time.Now())
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"online-judge/internal/models"
+)
+
+// generationJob asks the worker to run the test script of a problem, check every input
+// with the validator and compute the expected outputs of generated tests with the
+// reference solution. Only tests written by hand are sent; generated ones are replaced.
+func (h *Handler) generationJob(problem *models.ProblemDB, testCases []models.ProblemTestCase, actorID int) models.ExecuteCodePayload {
+	manual := make([]models.ProblemTestCase, 0, len(testCases))
+	for _, tc := range testCases {
+		if tc.Generator == "" {
+			manual = append(manual, tc)
+		}
+	}
+	job := h.validationJob(problem, manual, actorID)
+	job.Generators = problem.Generators
+	job.TestScript = problem.TestScript
+	job.ValidatorCode = problem.ValidatorCode
+	job.Solutions = nil
+	job.ExecutionType = models.ExecutionTypeGeneration
+	return job
+}
+
+// checkTestScript makes sure every line of the test script names a known generator.
+func checkTestScript(problem *models.ProblemDB) error {
+	generators := make(map[string]bool, len(problem.Generators))
+	for _, g := range problem.Generators {
+		if g.Name == "" || strings.ContainsAny(g.Name, " \t/") {
+			return fmt.Errorf("invalid generator name %q", g.Name)
+		}
+		generators[g.Name] = true
+	}
+	for i, line := range problem.TestScript {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			return fmt.Errorf("test script line %d is empty", i+1)
+		}
+		if !generators[fields[0]] {
+			return fmt.Errorf("test script line %d: unknown generator %q", i+1, fields[0])
+		}
+	}
+	return nil
+}
+
+// handleGenerationResult stores the generated tests and enqueues the validation of the
+// problem, or fails the validation when a generator, the validator or the reference
+// solution rejected a test.
+func (h *Handler) handleGenerationResult(ctx context.Context, ecr *models.ExecuteCodeResponse) {
+	if ecr.SystemError != "" {
+		h.failValidation(ctx, ecr.ID, "test generation failed: "+ecr.SystemError, nil)
+		return
+	}
+
+	generated := make([]models.ProblemTestCase, 0, len(ecr.TestCaseResults))
+	for i, res := range ecr.TestCaseResults {
+		if res.Status != "Accepted" {
+			name := fmt.Sprintf("test %d", i+1)
+			if res.Generator != "" {
+				name = fmt.Sprintf("%q", res.Generator)
+			}
+			h.failValidation(ctx, ecr.ID, fmt.Sprintf("%s: %s", name, res.Status), ecr.TestCaseResults)
+			return
+		}
+		if res.Generator != "" {
+			generated = append(generated, models.ProblemTestCase{
+				Input:          res.Input,
+				ExpectedOutput: res.ExpectedOutput,
+				Generator:      res.Generator,
+			})
+		}
+	}
+
+	testCases, err := h.problemRepo.ReplaceGeneratedTestCases(ctx, ecr.ID, generated)
+	if err != nil {
+		log.Println("Failed to store generated tests: ", err)
+		h.failValidation(ctx, ecr.ID, "storing generated tests failed: "+err.Error(), nil)
+		return
+	}
+	problem, err := h.problemRepo.GetProblemMetadata(ctx, ecr.ID, false)
+	if err != nil {
+		log.Println("Generation result for unknown problem: ", ecr.ID)
+		h.failValidation(ctx, ecr.ID, "loading the problem failed: "+err.Error(), nil)
+		return
+	}
+	if len(testCases) == 0 {
+		h.failValidation(ctx, ecr.ID, "problem has no test cases", nil)
+		return
+	}
+	h.enqueueValidation(ctx, h.validationJob(problem, testCases, 0))
+}
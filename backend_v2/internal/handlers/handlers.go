@@ -1,251 +1,814 @@
-package handlers
-
-import (
-	"context"
-	"encoding/json"
-	"log"
-	"net/http"
-	"strconv"
-	"time"
-
-	"online-judge/internal/middleware"
-	"online-judge/internal/models"
-	"online-judge/internal/repo"
-	"online-judge/internal/services"
-
-	"github.com/go-chi/chi/v5"
-)
-
-func handleAuth(user models.UserDB, w http.ResponseWriter) {
-	info := models.UserInfo{
-		Username: user.Username,
-		Email:    user.Email,
-	}
-
-	token := "generate from info"
-
-	cookie := &http.Cookie{
-		Name:     "auth_token",
-		Value:    token,
-		Expires:  time.Now().Add(24 * time.Hour), // Set expiration time
-		HttpOnly: true,                           // Make cookie accessible only by the server
-		Secure:   false,                          // Ensure cookie is sent only over HTTPS in production
-		SameSite: http.SameSiteStrictMode,        // recommended, prevent CSRF
-		Path:     "/",
-	}
-
-	http.SetCookie(w, cookie)
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(info)
-}
-
-type Handler struct {
-	submissionRepo *repo.SubmissionRepo
-	problemRepo    *repo.ProblemRepo
-	redisService   *services.RedisService
-}
-
-func NewHandler(submissionRepo *repo.SubmissionRepo,
-	problemRepo *repo.ProblemRepo,
-	redisService *services.RedisService) (*Handler, error) {
-	return &Handler{
-		submissionRepo: submissionRepo,
-		problemRepo:    problemRepo,
-		redisService:   redisService,
-	}, nil
-}
-
-func (h *Handler) validateProblem(ctx context.Context, problemID int) error {
-	problem, err := h.problemRepo.GetProblemMetadata(ctx, problemID)
-	if err != nil {
-		return err
-	}
-
-	testCases, err := h.problemRepo.GetProblemTestCases(ctx, problemID)
-	if err != nil {
-		return err
-	}
-
-	// TODO: obtain language from ID
-	language := "python"
-	if err := h.redisService.ExecuteCode(ctx, language, models.ExecuteCodePayload{
-		ID:             problemID,
-		Code:           problem.SolutionCode,
-		TestCases:      testCases,
-		RuntimeLimitMS: problem.MemoryLimitKB,
-		MemoryLimitKB:  problem.MemoryLimitKB,
-		ExecutionType:  "validation",
-	}); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
-	var payload models.SignupPayload
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	// add user to the database
-	user := models.UserDB{Username: "some", Email: "some@one.in", ID: 1, Password: "", IsAdmin: true, IsActive: true}
-
-	handleAuth(user, w)
-	w.WriteHeader(http.StatusCreated)
-}
-
-func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
-	var payload models.LoginPayload
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	// find user from database
-	user := models.UserDB{Username: "some", Email: "some@one.in", ID: 1, Password: "", IsAdmin: true, IsActive: true}
-
-	handleAuth(user, w)
-	w.WriteHeader(http.StatusCreated)
-}
-
-func (h *Handler) GetProblemList(w http.ResponseWriter, r *http.Request) {
-
-	// handle filters
-
-	problems, err := h.problemRepo.GetProblems(r.Context(), true)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(problems)
-}
-
-func (h *Handler) ViewProblem(w http.ResponseWriter, r *http.Request) {
-	problemID := 1 // from query
-	problem, err := h.problemRepo.GetProblemByID(r.Context(), problemID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(problem)
-}
-
-func (h *Handler) CreateProblem(w http.ResponseWriter, r *http.Request) {
-	var payload models.ProblemDB
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	id, err := h.problemRepo.CreateProblem(r.Context(), &payload)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
-	}
-
-	err = h.validateProblem(r.Context(), id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]int{"id": id})
-}
-
-func (h *Handler) UpdateProblem(w http.ResponseWriter, r *http.Request) {
-	var payload models.ProblemDB
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	err := h.problemRepo.UpdateProblemByID(r.Context(), &payload)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
-	}
-
-	err = h.validateProblem(r.Context(), payload.ID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("ok"))
-}
-
-func (h *Handler) SubmitCode(w http.ResponseWriter, r *http.Request) {
-	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
-	if !ok {
-		log.Println("Raw : ", r.Context().Value(middleware.UserIDKey))
-		http.Error(w, "invalid token (userID)", http.StatusBadRequest)
-		return
-	}
-
-	var payload models.SubmitCodePayload
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	problem, err := h.problemRepo.GetProblemMetadata(r.Context(), payload.ProblemID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	testCases, err := h.problemRepo.GetProblemTestCases(r.Context(), payload.ProblemID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	submissionId, err := h.submissionRepo.NewSubmission(r.Context(), payload.ProblemID, userID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	// TODO: obtain language from ID
-	language := "python"
-	if err := h.redisService.ExecuteCode(r.Context(), language, models.ExecuteCodePayload{
-		ID:             submissionId,
-		Code:           payload.Code,
-		TestCases:      testCases,
-		RuntimeLimitMS: problem.MemoryLimitKB,
-		MemoryLimitKB:  problem.MemoryLimitKB,
-		ExecutionType:  "submission",
-	}); err != nil {
-		http.Error(w, "error submitting the code: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.SubmitCodeResponse{SubmissionID: submissionId})
-}
-
-func (h *Handler) GetSubmissionResultByID(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "submissionID")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid submission ID", http.StatusBadRequest)
-		return
-	}
-
-	result, err := h.submissionRepo.GetSubmission(r.Context(), id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
-}
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	authmodule "online-judge/internal/auth_module"
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/realtime"
+	"online-judge/internal/repo"
+	"online-judge/internal/services"
+	"online-judge/internal/webhooks"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const minPasswordLength = 8
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,32}$`)
+
+// handleAuth issues a JWT for the user and sends it both as the auth_token cookie and in
+// the response body.
+func (h *Handler) handleAuth(user models.UserDB, w http.ResponseWriter, status int) {
+	token, err := h.auth.GetToken(strconv.Itoa(user.ID))
+	if err != nil {
+		http.Error(w, "error issuing a token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	cookie := &http.Cookie{
+		Name:     middleware.SessionCookie,
+		Value:    token,
+		Expires:  time.Now().Add(24 * time.Hour), // Set expiration time
+		HttpOnly: true,                           // Make cookie accessible only by the server
+		Secure:   false,                          // Ensure cookie is sent only over HTTPS in production
+		SameSite: http.SameSiteStrictMode,        // recommended, prevent CSRF
+		Path:     "/",
+	}
+
+	http.SetCookie(w, cookie)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.AuthResponse{
+		UserInfo: models.UserInfo{Username: user.Username, Email: user.Email},
+		Token:    token,
+	})
+}
+
+type Handler struct {
+	submissionRepo *repo.SubmissionRepo
+	problemRepo    *repo.ProblemRepo
+	runRepo        *repo.RunRepo
+	rejudgeRepo    *repo.RejudgeRepo
+	contestRepo    *repo.ContestRepo
+	userRepo       *repo.UserRepo
+	webhookRepo    *repo.WebhookRepo
+	tokenRepo      *repo.TokenRepo
+	hub            *realtime.Hub
+	webhooks       *webhooks.Dispatcher
+	redisService   *services.RedisService
+	queueLimits    services.QueueLimits
+	timeLimits     services.TimeLimits
+	auth           *authmodule.JWTAuth
+}
+
+func NewHandler(submissionRepo *repo.SubmissionRepo,
+	problemRepo *repo.ProblemRepo,
+	runRepo *repo.RunRepo,
+	rejudgeRepo *repo.RejudgeRepo,
+	contestRepo *repo.ContestRepo,
+	userRepo *repo.UserRepo,
+	webhookRepo *repo.WebhookRepo,
+	tokenRepo *repo.TokenRepo,
+	hub *realtime.Hub,
+	dispatcher *webhooks.Dispatcher,
+	redisService *services.RedisService,
+	queueLimits services.QueueLimits,
+	timeLimits services.TimeLimits,
+	auth *authmodule.JWTAuth) (*Handler, error) {
+	return &Handler{
+		submissionRepo: submissionRepo,
+		problemRepo:    problemRepo,
+		runRepo:        runRepo,
+		rejudgeRepo:    rejudgeRepo,
+		contestRepo:    contestRepo,
+		userRepo:       userRepo,
+		webhookRepo:    webhookRepo,
+		tokenRepo:      tokenRepo,
+		hub:            hub,
+		webhooks:       dispatcher,
+		redisService:   redisService,
+		queueLimits:    queueLimits,
+		timeLimits:     timeLimits,
+		auth:           auth,
+	}, nil
+}
+
+// validateProblem moves a draft problem to "Validating" and enqueues its reference
+// solution. Problems with generators or a validator first go through a generation job,
+// whose result enqueues the validation. The outcome is recorded by the result worker.
+func (h *Handler) validateProblem(ctx context.Context, problemID, actorID int) error {
+	problem, err := h.problemRepo.GetProblemMetadata(ctx, problemID, false)
+	if err != nil {
+		return err
+	}
+
+	testCases, err := h.problemRepo.GetProblemTestCases(ctx, problemID)
+	if err != nil {
+		return err
+	}
+	if len(testCases) == 0 && len(problem.TestScript) == 0 {
+		return errors.New("problem has no test cases")
+	}
+	if problem.RuntimeLimitMS <= 0 {
+		return errors.New("problem time limit must be positive")
+	}
+	if err := checkTestScript(problem); err != nil {
+		return err
+	}
+	if err := checkSolutionTags(problem); err != nil {
+		return err
+	}
+
+	if err := h.transitionProblem(ctx, problemID, models.ProblemStatusValidating, actorID, "submitted for validation"); err != nil {
+		return err
+	}
+
+	job := h.validationJob(problem, testCases, actorID)
+	if len(problem.TestScript) > 0 || problem.ValidatorCode != "" {
+		job = h.generationJob(problem, testCases, actorID)
+	}
+	return h.enqueueValidation(ctx, job)
+}
+
+// validationJob runs the reference solution on every test of a problem. When time limits
+// are set automatically, it runs under a generous limit so the solution can be timed.
+func (h *Handler) validationJob(problem *models.ProblemDB, testCases []models.ProblemTestCase, actorID int) models.ExecuteCodePayload {
+	runtimeLimit := h.timeLimits.ForLanguage(problem.RuntimeLimitMS, languageName(problem.SolutionLanguageID))
+	if h.timeLimits.Auto {
+		runtimeLimit = max(runtimeLimit, services.CalibrationLimitMS)
+	}
+	return models.ExecuteCodePayload{
+		ID:             problem.ID,
+		UserID:         actorID,
+		Code:           problem.SolutionCode,
+		TestCases:      testCases,
+		RuntimeLimitMS: runtimeLimit,
+		MemoryLimitKB:  problem.MemoryLimitKB,
+		CheckerCode:    problem.CheckerCode,
+		Solutions:      problem.Solutions,
+		ExecutionType:  models.ExecutionTypeValidation,
+	}
+}
+
+// enqueueValidation enqueues a validation or generation job, failing the validation of
+// the problem when that is not possible.
+func (h *Handler) enqueueValidation(ctx context.Context, job models.ExecuteCodePayload) error {
+	// TODO: obtain language from ID
+	language := "python"
+	if err := h.redisService.ExecuteCode(ctx, language, job); err != nil {
+		h.failValidation(ctx, job.ID, "failed to enqueue validation: "+err.Error(), nil)
+		return err
+	}
+	return nil
+}
+
+// failValidation records a failed validation report and moves the problem to "ValidationFailed".
+func (h *Handler) failValidation(ctx context.Context, problemID int, message string, results []models.TestCaseResult) {
+	_ = h.problemRepo.SaveValidationReport(ctx, models.ProblemValidationReport{
+		ProblemID:       problemID,
+		Passed:          false,
+		Message:         message,
+		TestCaseResults: results,
+		CreatedAt:       time.Now(),
+	})
+	_ = h.transitionProblem(ctx, problemID, models.ProblemStatusValidationFailed, 0, message)
+}
+
+// pendingRetryAfter is suggested to users at their pending submission cap, about the time
+// a submission takes to be judged.
+const pendingRetryAfter = 5 * time.Second
+
+// tooManyRequests replies 429 with a Retry-After header in whole seconds.
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration, message string) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	http.Error(w, message, http.StatusTooManyRequests)
+}
+
+func problemIDFromURL(r *http.Request) (int, error) {
+	return strconv.Atoi(chi.URLParam(r, "problemID"))
+}
+
+func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
+	var payload models.SignupPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case !usernamePattern.MatchString(payload.Username):
+		http.Error(w, "username must be 3 to 32 letters, digits, '_' or '-'", http.StatusBadRequest)
+		return
+	case !strings.Contains(payload.Email, "@"):
+		http.Error(w, "invalid email", http.StatusBadRequest)
+		return
+	case len(payload.Password) < minPasswordLength:
+		http.Error(w, fmt.Sprintf("password must be at least %d characters", minPasswordLength), http.StatusBadRequest)
+		return
+	case payload.Password != payload.ConfirmPassword:
+		http.Error(w, "passwords do not match", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := authmodule.HashPassword(payload.Password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	user := models.UserDB{Username: payload.Username, Email: payload.Email, Password: hash}
+	if user.ID, err = h.userRepo.CreateUser(r.Context(), user); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	h.handleAuth(user, w, http.StatusCreated)
+}
+
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var payload models.LoginPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userRepo.GetUserByUsername(r.Context(), payload.Username)
+	if err != nil || !authmodule.CheckPassword(user.Password, payload.Password) {
+		http.Error(w, "invalid username or password", http.StatusUnauthorized)
+		return
+	}
+	if !user.IsActive {
+		http.Error(w, "account is disabled", http.StatusForbidden)
+		return
+	}
+
+	h.handleAuth(*user, w, http.StatusOK)
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// parseProblemListQuery reads the filters, sorting and pagination of GET /problems:
+// ?difficulty=easy&tags=array,graph&tag_match=all&solved=false&search=sum&sort=acceptance&order=desc&page=2&page_size=10
+func parseProblemListQuery(r *http.Request) (models.ProblemListQuery, error) {
+	q := r.URL.Query()
+	query := models.ProblemListQuery{
+		Difficulty: strings.TrimSpace(q.Get("difficulty")),
+		Search:     strings.TrimSpace(q.Get("search")),
+		SortBy:     "id",
+	}
+
+	for _, tag := range strings.Split(q.Get("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			query.Tags = append(query.Tags, tag)
+		}
+	}
+
+	switch q.Get("tag_match") {
+	case "", "any":
+	case "all":
+		query.MatchAllTag = true
+	default:
+		return query, errors.New("tag_match must be one of: any, all")
+	}
+
+	if v := q.Get("solved"); v != "" {
+		solved, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, errors.New("solved must be true or false")
+		}
+		query.Solved = &solved
+	}
+
+	switch v := q.Get("sort"); v {
+	case "":
+	case "id", "acceptance", "difficulty":
+		query.SortBy = v
+	default:
+		return query, errors.New("sort must be one of: id, acceptance, difficulty")
+	}
+
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, errors.New("order must be one of: asc, desc")
+	}
+
+	var err error
+	query.Page, query.PageSize, err = parsePagination(q)
+	return query, err
+}
+
+// parsePagination reads ?page= (1-based) and ?page_size=, applying the defaults when absent.
+func parsePagination(q url.Values) (page, pageSize int, err error) {
+	page, pageSize = 1, defaultPageSize
+
+	if v := q.Get("page"); v != "" {
+		page, err = strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return 0, 0, errors.New("page must be a positive integer")
+		}
+	}
+
+	if v := q.Get("page_size"); v != "" {
+		pageSize, err = strconv.Atoi(v)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			return 0, 0, fmt.Errorf("page_size must be between 1 and %d", maxPageSize)
+		}
+	}
+
+	return page, pageSize, nil
+}
+
+func (h *Handler) GetProblemList(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+	isAdmin, _ := r.Context().Value(middleware.IsAdminKey).(bool)
+
+	query, err := parseProblemListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query.UserID = userID
+	query.HiddenIDs = h.contestRepo.HiddenProblemIDs(r.Context(), time.Now())
+
+	problems, err := h.problemRepo.GetProblems(r.Context(), isAdmin, query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(problems)
+}
+
+// SearchProblems handles GET /problems/search?q=shortest+path&page=1&page_size=20
+func (h *Handler) SearchProblems(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+	isAdmin, _ := r.Context().Value(middleware.IsAdminKey).(bool)
+
+	page, pageSize, err := parsePagination(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	text := strings.TrimSpace(r.URL.Query().Get("q"))
+	if text == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+
+	hidden := h.contestRepo.HiddenProblemIDs(r.Context(), time.Now())
+	result, err := h.problemRepo.SearchProblems(r.Context(), userID, isAdmin, hidden, text, page, pageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *Handler) ViewProblem(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+	isAdmin, _ := r.Context().Value(middleware.IsAdminKey).(bool)
+
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+	if !h.canAccessProblem(r.Context(), problemID, userID, isAdmin) {
+		http.Error(w, "problem not found", http.StatusNotFound)
+		return
+	}
+
+	if _, err := h.playableProblem(r.Context(), problemID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	problem, err := h.problemRepo.GetProblemByID(r.Context(), problemID, userID, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(problem)
+}
+
+func (h *Handler) CreateProblem(w http.ResponseWriter, r *http.Request) {
+	var payload models.ProblemDB
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	id, err := h.problemRepo.CreateProblem(r.Context(), &payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	err = h.validateProblem(r.Context(), id, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"id": id})
+}
+
+func (h *Handler) UpdateProblem(w http.ResponseWriter, r *http.Request) {
+	var payload models.ProblemDB
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	err := h.problemRepo.UpdateProblemByID(r.Context(), &payload, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	err = h.validateProblem(r.Context(), payload.ID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+func (h *Handler) reviewProblem(w http.ResponseWriter, r *http.Request, to models.ProblemStatus, commentRequired bool) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+
+	var payload models.ReviewProblemPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && err != io.EOF {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if commentRequired && strings.TrimSpace(payload.Comment) == "" {
+		http.Error(w, "a reviewer comment is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.transitionProblem(r.Context(), problemID, to, userID, payload.Comment); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// ApproveProblem publishes a problem that passed validation (or re-activates an inactive one).
+func (h *Handler) ApproveProblem(w http.ResponseWriter, r *http.Request) {
+	h.reviewProblem(w, r, models.ProblemStatusActive, false)
+}
+
+// RejectProblem rejects a problem awaiting review. A reviewer comment is mandatory.
+func (h *Handler) RejectProblem(w http.ResponseWriter, r *http.Request) {
+	h.reviewProblem(w, r, models.ProblemStatusRejected, true)
+}
+
+// DeactivateProblem hides an active problem from users.
+func (h *Handler) DeactivateProblem(w http.ResponseWriter, r *http.Request) {
+	h.reviewProblem(w, r, models.ProblemStatusInactive, false)
+}
+
+func (h *Handler) GetProblemStatusHistory(w http.ResponseWriter, r *http.Request) {
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.problemRepo.GetProblemTransitions(r.Context(), problemID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+func (h *Handler) GetProblemValidationReport(w http.ResponseWriter, r *http.Request) {
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.problemRepo.GetValidationReport(r.Context(), problemID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *Handler) SubmitCode(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		log.Println("Raw : ", r.Context().Value(middleware.UserIDKey))
+		http.Error(w, "invalid token (userID)", http.StatusBadRequest)
+		return
+	}
+
+	var payload models.SubmitCodePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// A retried request with the same Idempotency-Key gets the original submission back.
+	idempotencyKey, replayID, ok := h.claimIdempotencyKey(w, r, "submit", userID, payload)
+	if !ok {
+		return
+	}
+	if replayID != 0 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.SubmitCodeResponse{SubmissionID: replayID})
+		return
+	}
+	var createdID int
+	defer func() {
+		h.finishIdempotencyKey(context.WithoutCancel(r.Context()), "submit", userID, idempotencyKey, createdID)
+	}()
+
+	problem, err := h.playableProblem(r.Context(), payload.ProblemID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Problems of a contest that has not ended only take submissions to that contest.
+	if payload.ContestID != 0 {
+		if status, err := h.checkContestSubmission(r.Context(), payload.ContestID, payload.ProblemID, userID); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+	} else if h.contestRepo.HiddenProblemIDs(r.Context(), time.Now())[payload.ProblemID] {
+		http.Error(w, "problem belongs to a contest that has not ended; submit it with its contest_id", http.StatusForbidden)
+		return
+	}
+
+	testCases, err := h.problemRepo.GetProblemTestCases(r.Context(), payload.ProblemID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(testCases) == 0 {
+		http.Error(w, "problem has no test cases", http.StatusBadRequest)
+		return
+	}
+
+	pending, err := h.submissionRepo.CountPending(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if pending >= h.queueLimits.MaxPendingPerUser {
+		tooManyRequests(w, pendingRetryAfter, fmt.Sprintf("at most %d submissions may be pending at once", h.queueLimits.MaxPendingPerUser))
+		return
+	}
+
+	// TODO: obtain language from ID
+	language := "python"
+
+	overloaded, retryAfter, err := h.queueOverload(r.Context(), language)
+	if err != nil {
+		http.Error(w, "error reading the queue: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if overloaded {
+		tooManyRequests(w, retryAfter, "the judge is overloaded, try again later")
+		return
+	}
+
+	allowed, retryAfter, err := h.redisService.AllowSubmission(r.Context(), userID, h.queueLimits)
+	if err != nil {
+		http.Error(w, "error checking the submission quota: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if !allowed {
+		tooManyRequests(w, retryAfter, "submission quota exceeded, try again later")
+		return
+	}
+
+	job := models.ExecuteCodePayload{
+		UserID:         userID,
+		Code:           payload.Code,
+		TestCases:      testCases,
+		RuntimeLimitMS: h.timeLimits.ForLanguage(problem.RuntimeLimitMS, languageName(payload.LanguageID)),
+		MemoryLimitKB:  problem.MemoryLimitKB,
+		CheckerCode:    problem.CheckerCode,
+		ExecutionType:  models.ExecutionTypeSubmission,
+	}
+	cacheKey := resultCacheHash(language, job)
+
+	submissionId, err := h.submissionRepo.NewSubmission(r.Context(), models.SubmissionDB{
+		UserID:         userID,
+		ProblemID:      payload.ProblemID,
+		LanguageID:     payload.LanguageID,
+		Code:           payload.Code,
+		ContestID:      payload.ContestID,
+		ResultCacheKey: cacheKey,
+	}, h.queueLimits.MaxPendingPerUser)
+	if errors.Is(err, repo.ErrTooManyPending) {
+		// Another request of the user took the last slot since the check above.
+		tooManyRequests(w, pendingRetryAfter, fmt.Sprintf("at most %d submissions may be pending at once", h.queueLimits.MaxPendingPerUser))
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Identical code already judged on the same tests gets its verdict without re-running.
+	if h.judgeFromCache(r.Context(), submissionId, cacheKey) {
+		createdID = submissionId
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.SubmitCodeResponse{SubmissionID: submissionId})
+		return
+	}
+
+	job.ID = submissionId
+	position, err := h.redisService.ExecuteCodeWithPosition(r.Context(), language, job)
+	if err != nil {
+		h.submissionRepo.UpdateSubmissionState(r.Context(), submissionId, models.SubmissionStateFailed, "", time.Now())
+		http.Error(w, "error submitting the code: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	createdID = submissionId
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.SubmitCodeResponse{
+		SubmissionID:         submissionId,
+		QueuePosition:        position,
+		EstimatedWaitSeconds: int(math.Ceil(h.estimateWait(r.Context(), h.jobsAhead(r.Context(), language, position)).Seconds())),
+	})
+}
+
+func (h *Handler) GetSubmissionResultByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "submissionID")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid submission ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.submissionRepo.GetSubmission(r.Context(), id, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetSubmissionTestResults returns the per-test results of a judged submission. Inputs and
+// outputs of hidden tests are redacted unless the caller is an admin.
+func (h *Handler) GetSubmissionTestResults(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+	isAdmin, _ := r.Context().Value(middleware.IsAdminKey).(bool)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "submissionID"))
+	if err != nil {
+		http.Error(w, "Invalid submission ID", http.StatusBadRequest)
+		return
+	}
+
+	submission, err := h.submissionRepo.GetSubmission(r.Context(), id, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if submission.UserID != userID && !isAdmin {
+		http.Error(w, "submission not found", http.StatusNotFound)
+		return
+	}
+
+	results := make([]models.TestCaseResult, len(submission.TestCaseResults))
+	for i, res := range submission.TestCaseResults {
+		if isAdmin {
+			results[i] = res
+		} else {
+			results[i] = res.Redact()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// ListSubmissions returns the caller's submission history, newest first:
+// ?problem_id=1&verdict=accepted&language=1&page=1&page_size=20
+func (h *Handler) ListSubmissions(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	q := r.URL.Query()
+	query := models.SubmissionListQuery{
+		UserID:  userID,
+		Verdict: strings.TrimSpace(q.Get("verdict")),
+	}
+
+	var err error
+	if v := q.Get("problem_id"); v != "" {
+		if query.ProblemID, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("language"); v != "" {
+		if query.LanguageID, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid language ID", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("contest_id"); v != "" {
+		if query.ContestID, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid contest ID", http.StatusBadRequest)
+			return
+		}
+	}
+	if query.Page, query.PageSize, err = parsePagination(q); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	submissions, err := h.submissionRepo.ListSubmissions(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submissions)
+}
+
+// GetSubmissionCode returns the source code of a past submission to its author or an admin.
+func (h *Handler) GetSubmissionCode(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+	isAdmin, _ := r.Context().Value(middleware.IsAdminKey).(bool)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "submissionID"))
+	if err != nil {
+		http.Error(w, "Invalid submission ID", http.StatusBadRequest)
+		return
+	}
+
+	submission, err := h.submissionRepo.GetSubmission(r.Context(), id, true)
+	if err != nil || (submission.UserID != userID && !isAdmin) {
+		http.Error(w, "submission not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.SubmissionCode{
+		SubmissionID: submission.ID,
+		ProblemID:    submission.ProblemID,
+		LanguageID:   submission.LanguageID,
+		Code:         submission.Code,
+		CreatedAt:    submission.CreatedAt,
+	})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"online-judge/internal/services"
+)
+
+const (
+	idempotencyHeader       = "Idempotency-Key"
+	idempotentReplayHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+)
+
+// claimIdempotencyKey reserves the request's Idempotency-Key, if it has one, for the
+// decoded payload of the request. When the key was already used with the same payload, it
+// returns the ID created by the first request so the caller can replay its response. It
+// writes the error response itself and returns ok=false when the request must stop here.
+// A claimed key must be passed to finishIdempotencyKey.
+func (h *Handler) claimIdempotencyKey(w http.ResponseWriter, r *http.Request, scope string, userID int, payload any) (key string, replayID int, ok bool) {
+	key = r.Header.Get(idempotencyHeader)
+	if key == "" {
+		return "", 0, true
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		http.Error(w, "Idempotency-Key is too long", http.StatusBadRequest)
+		return "", 0, false
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", 0, false
+	}
+	sum := sha256.Sum256(body)
+
+	replayID, claimed, err := h.redisService.ClaimIdempotencyKey(r.Context(), scope, userID, key, hex.EncodeToString(sum[:]))
+	if errors.Is(err, services.ErrIdempotencyKeyReused) {
+		http.Error(w, "Idempotency-Key was already used with a different request body", http.StatusUnprocessableEntity)
+		return "", 0, false
+	}
+	if err != nil {
+		http.Error(w, "error checking the Idempotency-Key: "+err.Error(), http.StatusServiceUnavailable)
+		return "", 0, false
+	}
+	if claimed {
+		return key, 0, true
+	}
+	if replayID == 0 {
+		http.Error(w, "a request with this Idempotency-Key is still in progress", http.StatusConflict)
+		return "", 0, false
+	}
+	w.Header().Set(idempotentReplayHeader, "true")
+	return "", replayID, true
+}
+
+// finishIdempotencyKey records the ID created for a claimed key, or releases the key when
+// the request failed (id is 0) so the client can retry it.
+func (h *Handler) finishIdempotencyKey(ctx context.Context, scope string, userID int, key string, id int) {
+	if key == "" {
+		return
+	}
+	var err error
+	if id == 0 {
+		err = h.redisService.ReleaseIdempotencyKey(ctx, scope, userID, key)
+	} else {
+		err = h.redisService.CompleteIdempotencyKey(ctx, scope, userID, key, id)
+	}
+	if err != nil {
+		log.Println("Failed to finish Idempotency-Key: ", err)
+	}
+}
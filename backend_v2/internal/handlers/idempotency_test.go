@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"online-judge/internal/services"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestClaimIdempotencyKey(t *testing.T) {
+	type payload struct{ Code string }
+
+	tests := []struct {
+		name       string
+		key        string
+		first      *payload // claimed beforehand with the same key, if set
+		finishedID int      // ID the first request finished with
+		payload    payload
+		redisDown  bool
+
+		wantOK      bool
+		wantClaimed bool
+		wantReplay  int
+		wantCode    int
+	}{
+		{name: "no key", payload: payload{"a"}, wantOK: true},
+		{name: "too long", key: strings.Repeat("k", maxIdempotencyKeyLength+1), payload: payload{"a"}, wantCode: http.StatusBadRequest},
+		{name: "first request", key: "k", payload: payload{"a"}, wantOK: true, wantClaimed: true},
+		{name: "replayed", key: "k", first: &payload{"a"}, finishedID: 7, payload: payload{"a"}, wantOK: true, wantReplay: 7},
+		{name: "in progress", key: "k", first: &payload{"a"}, payload: payload{"a"}, wantCode: http.StatusConflict},
+		{name: "different body", key: "k", first: &payload{"a"}, finishedID: 7, payload: payload{"b"}, wantCode: http.StatusUnprocessableEntity},
+		{name: "redis down", key: "k", payload: payload{"a"}, redisDown: true, wantCode: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			redisService := services.NewRedisService(mr.Addr())
+			defer redisService.Close()
+			h := &Handler{redisService: redisService}
+
+			newRequest := func() *http.Request {
+				r := httptest.NewRequest("POST", "/api/submit", nil)
+				if tt.key != "" {
+					r.Header.Set(idempotencyHeader, tt.key)
+				}
+				return r
+			}
+			if tt.first != nil {
+				key, _, ok := h.claimIdempotencyKey(httptest.NewRecorder(), newRequest(), "submit", 2, *tt.first)
+				if !ok || key == "" {
+					t.Fatal("the first request did not claim the key")
+				}
+				if tt.finishedID != 0 {
+					h.finishIdempotencyKey(context.Background(), "submit", 2, key, tt.finishedID)
+				}
+			}
+			if tt.redisDown {
+				mr.Close()
+			}
+
+			w := httptest.NewRecorder()
+			key, replayID, ok := h.claimIdempotencyKey(w, newRequest(), "submit", 2, tt.payload)
+			if ok != tt.wantOK || (key != "") != tt.wantClaimed || replayID != tt.wantReplay {
+				t.Errorf("claimIdempotencyKey = %q, %d, %v, want claimed %v, %d, %v",
+					key, replayID, ok, tt.wantClaimed, tt.wantReplay, tt.wantOK)
+			}
+			if !ok && w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if replayed := w.Header().Get(idempotentReplayHeader) == "true"; replayed != (tt.wantReplay != 0) {
+				t.Errorf("%s header set = %v, want %v", idempotentReplayHeader, replayed, tt.wantReplay != 0)
+			}
+		})
+	}
+}
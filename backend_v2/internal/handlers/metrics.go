@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const defaultMetricsWindow = time.Hour
+
+// GetQueueMetrics reports how many recent submissions are in each state together with
+// queue-wait and judge-duration percentiles and the depth of each priority lane. The
+// window defaults to one hour and can be set with ?window=15m.
+func (h *Handler) GetQueueMetrics(w http.ResponseWriter, r *http.Request) {
+	window := defaultMetricsWindow
+	if v := r.URL.Query().Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "window must be a positive duration, e.g. 15m", http.StatusBadRequest)
+			return
+		}
+		window = d
+	}
+
+	metrics, err := h.submissionRepo.GetQueueMetrics(r.Context(), window)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// TODO: report every language once languages are resolved from their ID
+	if metrics.LaneDepths, err = h.redisService.QueueDepths(r.Context(), "python"); err != nil {
+		http.Error(w, "error reading queue depths: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metrics)
+}
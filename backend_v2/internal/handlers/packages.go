@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/problempkg"
+)
+
+// ImportProblem creates a problem from a package sent as the "file" field of a multipart
+// form, and submits it for validation. See package problempkg for the layout.
+func (h *Handler) ImportProblem(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	pkg, ok := readProblemPackage(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := h.problemRepo.CreateProblem(r.Context(), &pkg.Problem)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := h.problemRepo.AddProblemTestCases(r.Context(), id, pkg.TestCases, true); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.validateProblem(r.Context(), id, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"id": id})
+}
+
+// ReimportProblem replaces a problem and its tests with a newer version of its package.
+// Judging statistics are kept, and the problem goes through validation again.
+func (h *Handler) ReimportProblem(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.problemRepo.GetProblemMetadata(r.Context(), problemID, false); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	pkg, ok := readProblemPackage(w, r)
+	if !ok {
+		return
+	}
+	problem := pkg.Problem
+	problem.ID = problemID
+
+	if err := h.problemRepo.UpdateProblemByID(r.Context(), &problem, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if _, err := h.problemRepo.AddProblemTestCases(r.Context(), problemID, pkg.TestCases, true); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.validateProblem(r.Context(), problemID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// ExportProblem downloads a problem with its tests, checker and solutions as a package.
+func (h *Handler) ExportProblem(w http.ResponseWriter, r *http.Request) {
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+	problem, err := h.problemRepo.GetProblemMetadata(r.Context(), problemID, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	testCases, err := h.problemRepo.GetProblemTestCases(r.Context(), problemID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := problempkg.Write(&buf, *problem, testCases); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	name := problem.Slug
+	if name == "" {
+		name = fmt.Sprintf("problem-%d", problem.ID)
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".zip"))
+	w.Write(buf.Bytes())
+}
+
+// readProblemPackage reads the package uploaded in the "file" form field, replying with
+// an error when it is not valid.
+func readProblemPackage(w http.ResponseWriter, r *http.Request) (*problempkg.Package, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxTestArchiveBytes)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "a zip archive is required in the \"file\" form field: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	defer file.Close()
+
+	archive, err := zip.NewReader(file, header.Size)
+	if err != nil {
+		http.Error(w, "invalid zip archive: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	pkg, err := problempkg.Read(archive)
+	if err != nil {
+		http.Error(w, "invalid problem package: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return pkg, true
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RejudgeSubmission runs a single judged submission again.
+func (h *Handler) RejudgeSubmission(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "submissionID"))
+	if err != nil {
+		http.Error(w, "Invalid submission ID", http.StatusBadRequest)
+		return
+	}
+	h.rejudge(w, r, models.RejudgeFilter{SubmissionID: id})
+}
+
+// RejudgeProblem runs every judged submission of a problem again.
+func (h *Handler) RejudgeProblem(w http.ResponseWriter, r *http.Request) {
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+	h.rejudge(w, r, models.RejudgeFilter{ProblemID: problemID})
+}
+
+// RejudgeRange runs the judged submissions created in a time window again. The body is a
+// RejudgeFilter with "from" and/or "to" in RFC 3339, optionally narrowed by "problem_id".
+func (h *Handler) RejudgeRange(w http.ResponseWriter, r *http.Request) {
+	var filter models.RejudgeFilter
+	if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.From == nil && filter.To == nil {
+		http.Error(w, "from or to is required", http.StatusBadRequest)
+		return
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+	h.rejudge(w, r, filter)
+}
+
+func (h *Handler) GetRejudge(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "rejudgeID"))
+	if err != nil {
+		http.Error(w, "Invalid rejudge ID", http.StatusBadRequest)
+		return
+	}
+
+	rejudge, err := h.rejudgeRepo.GetRejudge(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rejudge)
+}
+
+// rejudge resets the submissions selected by filter and enqueues them with the
+// ExecutionTypeRejudge type, which the execution service serves at low priority.
+// Verdict changes are collected by handleSubmissionResult.
+func (h *Handler) rejudge(w http.ResponseWriter, r *http.Request, filter models.RejudgeFilter) {
+	actorID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	previous, err := h.submissionRepo.ResetForRejudge(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	rejudgeID, err := h.rejudgeRepo.NewRejudge(r.Context(), actorID, filter, previous)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	testCases := make(map[int][]models.ProblemTestCase)
+	problems := make(map[int]*models.ProblemDB)
+	for _, s := range previous {
+		if _, ok := problems[s.ProblemID]; !ok {
+			problems[s.ProblemID], _ = h.problemRepo.GetProblemMetadata(r.Context(), s.ProblemID, false)
+			testCases[s.ProblemID], _ = h.problemRepo.GetProblemTestCases(r.Context(), s.ProblemID)
+		}
+
+		problem := problems[s.ProblemID]
+		if problem == nil || len(testCases[s.ProblemID]) == 0 {
+			h.failRejudge(r.Context(), s.ID, "problem has no test cases")
+			continue
+		}
+
+		// TODO: obtain language from ID
+		language := "python"
+		if err := h.redisService.ExecuteCode(r.Context(), language, models.ExecuteCodePayload{
+			ID:             s.ID,
+			UserID:         s.UserID,
+			Code:           s.Code,
+			TestCases:      testCases[s.ProblemID],
+			RuntimeLimitMS: h.timeLimits.ForLanguage(problem.RuntimeLimitMS, languageName(s.LanguageID)),
+			MemoryLimitKB:  problem.MemoryLimitKB,
+			CheckerCode:    problem.CheckerCode,
+			ExecutionType:  models.ExecutionTypeRejudge,
+		}); err != nil {
+			h.failRejudge(r.Context(), s.ID, err.Error())
+		}
+	}
+
+	rejudge, err := h.rejudgeRepo.GetRejudge(r.Context(), rejudgeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(rejudge)
+}
+
+// failRejudge records a rejudged submission that could not be enqueued as a system error.
+func (h *Handler) failRejudge(ctx context.Context, submissionID int, reason string) {
+	status := "System Error: " + reason
+	if err := h.submissionRepo.UpdateSubmission(ctx, submissionID, models.SubmissionJudgement{
+		State:   models.SubmissionStateFailed,
+		Status:  status,
+		Verdict: "System Error",
+	}); err != nil {
+		log.Println("Failed to update submission: ", err)
+	} else {
+		h.publishVerdict(ctx, submissionID)
+	}
+	h.recordRejudgeResult(ctx, submissionID, "System Error", status)
+}
+
+// recordRejudgeResult stores the new verdict of a rejudged submission and rebuilds the
+// problem and user statistics from the current verdicts, so that they stay right even
+// when some job of the rejudge never reports back.
+func (h *Handler) recordRejudgeResult(ctx context.Context, submissionID int, verdict, status string) {
+	rejudgeID, completed, err := h.rejudgeRepo.RecordResult(ctx, submissionID, verdict, status)
+	if err != nil {
+		log.Println("Failed to record rejudge result: ", err)
+		return
+	}
+
+	judged, err := h.submissionRepo.GetJudgedSubmissions(ctx)
+	if err == nil {
+		err = h.problemRepo.RecomputeStats(ctx, judged)
+	}
+	if err == nil {
+		err = h.rebuildLeaderboard(ctx)
+	}
+	if err != nil {
+		log.Println("Failed to recompute stats after rejudge: ", err)
+		return
+	}
+	if completed {
+		log.Printf("Rejudge %d completed", rejudgeID)
+	}
+}
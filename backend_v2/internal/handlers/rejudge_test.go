@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"online-judge/internal/models"
+	"online-judge/internal/repo"
+	"online-judge/internal/services"
+	"online-judge/internal/webhooks"
+)
+
+func TestRejudgeRecomputesStats(t *testing.T) {
+	accepted := []models.TestCaseResult{{Status: "Accepted"}}
+	wrong := []models.TestCaseResult{{Status: "Wrong Answer"}}
+
+	tests := []struct {
+		name                    string
+		rejudged                map[int][]models.TestCaseResult // user ID -> new results
+		total, accepted, solved int
+		changes                 int
+		completed               bool
+	}{
+		// The statistics are rebuilt on each new verdict, leaving out submissions that are
+		// still waiting for theirs.
+		{name: "nothing reported", total: 2, accepted: 1, solved: 1},
+		{name: "one reported, unchanged", rejudged: map[int][]models.TestCaseResult{2: wrong}, total: 1},
+		{
+			name:     "verdicts swapped",
+			rejudged: map[int][]models.TestCaseResult{2: accepted, 3: wrong},
+			total:    2, accepted: 1, solved: 1, changes: 2, completed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisService := services.NewRedisService("127.0.0.1:1")
+			defer redisService.Close()
+			h := &Handler{
+				submissionRepo: repo.NewSubmissionRepo(),
+				problemRepo:    repo.NewProblemRepo(),
+				rejudgeRepo:    repo.NewRejudgeRepo(),
+				webhooks:       webhooks.NewDispatcher(repo.NewWebhookRepo()),
+				redisService:   redisService,
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			// User 2 got a wrong answer and user 3 solved the problem.
+			ids := make(map[int]int)
+			for userID, results := range map[int][]models.TestCaseResult{2: wrong, 3: accepted} {
+				id, err := h.submissionRepo.NewSubmission(ctx, models.SubmissionDB{UserID: userID, ProblemID: 1, LanguageID: 2}, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids[userID] = id
+				h.HandleExecutionResult(ctx, &models.ExecuteCodeResponse{
+					ID: id, ExecutionType: models.ExecutionTypeSubmission, TestCaseResults: results,
+				})
+			}
+
+			previous, err := h.submissionRepo.ResetForRejudge(ctx, models.RejudgeFilter{ProblemID: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			rejudgeID, err := h.rejudgeRepo.NewRejudge(ctx, 1, models.RejudgeFilter{ProblemID: 1}, previous)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for userID, results := range tt.rejudged {
+				h.HandleExecutionResult(ctx, &models.ExecuteCodeResponse{
+					ID: ids[userID], ExecutionType: models.ExecutionTypeRejudge, TestCaseResults: results,
+				})
+			}
+
+			p, err := h.problemRepo.GetProblemMetadata(ctx, 1, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.TotalSubmissions != tt.total || p.AcceptedSubmissions != tt.accepted || p.SolvedCount != tt.solved {
+				t.Errorf("problem counters = %d/%d/%d, want %d/%d/%d",
+					p.TotalSubmissions, p.AcceptedSubmissions, p.SolvedCount, tt.total, tt.accepted, tt.solved)
+			}
+			rejudge, err := h.rejudgeRepo.GetRejudge(ctx, rejudgeID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rejudge.Changes) != tt.changes || (rejudge.CompletedAt != nil) != tt.completed {
+				t.Errorf("rejudge has %d changes, completed %v, want %d, %v",
+					len(rejudge.Changes), rejudge.CompletedAt != nil, tt.changes, tt.completed)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"online-judge/internal/models"
+)
+
+// cacheWorkerID is reported as the worker of results served from the cache.
+const cacheWorkerID = "cache"
+
+// resultCacheHash identifies a judge job by everything that determines its verdict: the
+// code, the language, the exact test set, the limits and the checker. Any change to a
+// problem's tests yields a new hash, which invalidates the results cached for the old
+// test set.
+func resultCacheHash(language string, job models.ExecuteCodePayload) string {
+	h := sha256.New()
+	writeField := func(s string) {
+		binary.Write(h, binary.LittleEndian, int64(len(s)))
+		h.Write([]byte(s))
+	}
+	writeInt := func(n int) {
+		binary.Write(h, binary.LittleEndian, int64(n))
+	}
+
+	writeField(language)
+	writeField(job.Code)
+	writeInt(job.RuntimeLimitMS)
+	writeInt(job.MemoryLimitKB)
+	writeField(job.CheckerCode)
+	writeInt(len(job.TestCases))
+	for _, tc := range job.TestCases {
+		writeInt(tc.ID)
+		writeField(tc.Input)
+		writeField(tc.ExpectedOutput)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheableResult reports whether a result may be reused for identical jobs. Time and
+// memory limit verdicts depend on the load of the worker, so they are judged again.
+func cacheableResult(ecr *models.ExecuteCodeResponse) bool {
+	for _, res := range ecr.TestCaseResults {
+		if res.Status == "TLE" || res.Status == "MLE" {
+			return false
+		}
+	}
+	return true
+}
+
+// judgeFromCache completes a submission with a cached result of an identical job. It
+// returns false when there is no usable cached result and the job must be enqueued.
+func (h *Handler) judgeFromCache(ctx context.Context, submissionID int, hash string) bool {
+	cached, ok, err := h.redisService.GetCachedResult(ctx, hash)
+	if err != nil {
+		log.Println("Failed to read the result cache: ", err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+
+	now := time.Now()
+	cached.ID = submissionID
+	cached.ExecutionType = models.ExecutionTypeSubmission
+	cached.WorkerID = cacheWorkerID
+	cached.StartedAt = now
+	cached.FinishedAt = now
+	h.HandleExecutionResult(ctx, cached)
+	return true
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"online-judge/internal/models"
+	"online-judge/internal/repo"
+	"online-judge/internal/services"
+	"online-judge/internal/webhooks"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestResultCacheHash(t *testing.T) {
+	base := models.ExecuteCodePayload{
+		ID:             1,
+		UserID:         2,
+		Code:           "print(1)",
+		TestCases:      []models.ProblemTestCase{{ID: 1, Input: "1", ExpectedOutput: "1"}},
+		RuntimeLimitMS: 1000,
+		MemoryLimitKB:  65536,
+		ExecutionType:  models.ExecutionTypeSubmission,
+	}
+	baseHash := resultCacheHash("python", base)
+
+	tests := []struct {
+		name     string
+		language string
+		change   func(job *models.ExecuteCodePayload)
+		same     bool
+	}{
+		{name: "other submission", change: func(job *models.ExecuteCodePayload) { job.ID, job.UserID = 9, 9 }, same: true},
+		{name: "language", language: "go"},
+		{name: "code", change: func(job *models.ExecuteCodePayload) { job.Code = "print(2)" }},
+		{name: "time limit", change: func(job *models.ExecuteCodePayload) { job.RuntimeLimitMS = 2000 }},
+		{name: "memory limit", change: func(job *models.ExecuteCodePayload) { job.MemoryLimitKB = 1024 }},
+		{name: "checker", change: func(job *models.ExecuteCodePayload) { job.CheckerCode = "exit(0)" }},
+		{name: "test input", change: func(job *models.ExecuteCodePayload) { job.TestCases[0].Input = "2" }},
+		{name: "test answer", change: func(job *models.ExecuteCodePayload) { job.TestCases[0].ExpectedOutput = "2" }},
+		{name: "test ID", change: func(job *models.ExecuteCodePayload) { job.TestCases[0].ID = 2 }},
+		{
+			name: "test added",
+			change: func(job *models.ExecuteCodePayload) {
+				job.TestCases = append(job.TestCases, models.ProblemTestCase{ID: 2})
+			},
+		},
+		{
+			// Fields are length-prefixed, so moving bytes between them changes the hash.
+			name: "bytes moved between fields",
+			change: func(job *models.ExecuteCodePayload) {
+				job.TestCases[0].Input, job.TestCases[0].ExpectedOutput = "11", ""
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := base
+			job.TestCases = append([]models.ProblemTestCase{}, base.TestCases...)
+			language := "python"
+			if tt.language != "" {
+				language = tt.language
+			}
+			if tt.change != nil {
+				tt.change(&job)
+			}
+			if same := resultCacheHash(language, job) == baseHash; same != tt.same {
+				t.Errorf("same hash = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+func TestCacheableResult(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		want     bool
+	}{
+		{[]string{"Accepted", "Accepted"}, true},
+		{[]string{"Accepted", "Wrong Answer"}, true},
+		{[]string{"Error"}, true},
+		{[]string{"Accepted", "TLE"}, false},
+		{[]string{"MLE"}, false},
+	}
+	for _, tt := range tests {
+		ecr := &models.ExecuteCodeResponse{}
+		for _, s := range tt.statuses {
+			ecr.TestCaseResults = append(ecr.TestCaseResults, models.TestCaseResult{Status: s})
+		}
+		if got := cacheableResult(ecr); got != tt.want {
+			t.Errorf("cacheableResult(%v) = %v, want %v", tt.statuses, got, tt.want)
+		}
+	}
+}
+
+func TestJudgeFromCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		first     []models.TestCaseResult // results of the first, judged submission; none if nil
+		cached    bool
+		wantState models.SubmissionState
+	}{
+		{name: "miss", wantState: models.SubmissionStateQueued},
+		{name: "accepted", first: []models.TestCaseResult{{Status: "Accepted"}}, cached: true, wantState: models.SubmissionStateJudged},
+		{name: "wrong answer", first: []models.TestCaseResult{{Status: "Wrong Answer"}}, cached: true, wantState: models.SubmissionStateJudged},
+		{name: "time limit not cached", first: []models.TestCaseResult{{Status: "TLE"}}, wantState: models.SubmissionStateQueued},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			redisService := services.NewRedisService(mr.Addr())
+			defer redisService.Close()
+			h := &Handler{
+				submissionRepo: repo.NewSubmissionRepo(),
+				problemRepo:    repo.NewProblemRepo(),
+				webhooks:       webhooks.NewDispatcher(repo.NewWebhookRepo()),
+				redisService:   redisService,
+			}
+			ctx := context.Background()
+			const hash = "job"
+
+			if tt.first != nil {
+				id, _ := h.submissionRepo.NewSubmission(ctx, models.SubmissionDB{UserID: 2, ProblemID: 1, ResultCacheKey: hash}, 0)
+				h.HandleExecutionResult(ctx, &models.ExecuteCodeResponse{
+					ID: id, ExecutionType: models.ExecutionTypeSubmission, WorkerID: "w1", TestCaseResults: tt.first,
+				})
+			}
+
+			id, _ := h.submissionRepo.NewSubmission(ctx, models.SubmissionDB{UserID: 3, ProblemID: 1, ResultCacheKey: hash}, 0)
+			if got := h.judgeFromCache(ctx, id, hash); got != tt.cached {
+				t.Fatalf("judgeFromCache = %v, want %v", got, tt.cached)
+			}
+
+			s, err := h.submissionRepo.GetSubmission(ctx, id, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.State != tt.wantState {
+				t.Errorf("state = %s, want %s", s.State, tt.wantState)
+			}
+			if tt.cached && (s.Verdict != tt.first[0].Status || s.WorkerID != cacheWorkerID) {
+				t.Errorf("verdict %q by %q, want %q by %q", s.Verdict, s.WorkerID, tt.first[0].Status, cacheWorkerID)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"online-judge/internal/models"
+)
+
+// HandleExecutionResult routes a result from the execution service by its ExecutionType.
+// It is the callback of RedisService.StartResultWorker.
+func (h *Handler) HandleExecutionResult(ctx context.Context, ecr *models.ExecuteCodeResponse) {
+	status, runtime, memory := "Accepted", 0, 0
+	for i, v := range ecr.TestCaseResults {
+		if v.RuntimeMS > runtime {
+			runtime = v.RuntimeMS
+		}
+		if v.MemoryKB > memory {
+			memory = v.MemoryKB
+		}
+		// Like the verdict, the status names the first failing test.
+		if v.Status != "Accepted" && status == "Accepted" {
+			status = fmt.Sprintf("%s on Test Case : %d", v.Status, i+1)
+		}
+	}
+	log.Println("Result received: ", status, runtime, memory)
+
+	switch ecr.ExecutionType {
+	case models.ExecutionTypeSubmission, models.ExecutionTypeRejudge:
+		h.handleSubmissionResult(ctx, ecr, status, runtime, memory)
+	case models.ExecutionTypeRun, models.ExecutionTypeRunReference:
+		h.HandleRunResult(ctx, ecr)
+	case models.ExecutionTypeValidation:
+		h.handleValidationResult(ctx, ecr, status)
+	case models.ExecutionTypeGeneration:
+		h.handleGenerationResult(ctx, ecr)
+	default:
+		log.Println("Result with unknown execution type: ", ecr.ExecutionType)
+	}
+}
+
+// handleSubmissionResult stores the verdict and per-test results of a submission. Tests are
+// flagged as samples so hidden ones can be redacted, and the first failing test is surfaced
+// when it is a sample.
+func (h *Handler) handleSubmissionResult(ctx context.Context, ecr *models.ExecuteCodeResponse, status string, runtime, memory int) {
+	submission, err := h.submissionRepo.GetSubmission(ctx, ecr.ID, true)
+	if err != nil {
+		log.Println("Result for unknown submission: ", ecr.ID)
+		return
+	}
+
+	samples := make(map[int]bool)
+	if testCases, err := h.problemRepo.GetProblemTestCases(ctx, submission.ProblemID); err == nil {
+		for _, tc := range testCases {
+			samples[tc.ID] = tc.IsSample
+		}
+	}
+
+	for i := range ecr.TestCaseResults {
+		ecr.TestCaseResults[i].IsSample = samples[ecr.TestCaseResults[i].ID]
+	}
+
+	verdict := "Accepted"
+	var failedTest *models.TestCaseResult
+	for _, res := range ecr.TestCaseResults {
+		if res.Status != "Accepted" {
+			verdict = res.Status
+			if res.IsSample {
+				failedTest = &res
+			}
+			break
+		}
+	}
+
+	state := models.SubmissionStateJudged
+	if ecr.SystemError != "" {
+		state = models.SubmissionStateFailed
+		status = "System Error: " + ecr.SystemError
+		verdict = "System Error"
+	}
+
+	if err := h.submissionRepo.UpdateSubmission(ctx, ecr.ID, models.SubmissionJudgement{
+		State:           state,
+		Status:          status,
+		Verdict:         verdict,
+		RuntimeMS:       runtime,
+		MemoryKB:        memory,
+		TestCaseResults: ecr.TestCaseResults,
+		FailedTest:      failedTest,
+		WorkerID:        ecr.WorkerID,
+		StartedAt:       ecr.StartedAt,
+		FinishedAt:      ecr.FinishedAt,
+	}); err != nil {
+		log.Println("Failed to update submission: ", err)
+		return
+	}
+	h.publishVerdict(ctx, ecr.ID)
+
+	if ecr.ExecutionType == models.ExecutionTypeSubmission && state == models.SubmissionStateJudged &&
+		submission.ResultCacheKey != "" && ecr.WorkerID != cacheWorkerID && cacheableResult(ecr) {
+		if err := h.redisService.CacheResult(ctx, submission.ResultCacheKey, ecr); err != nil {
+			log.Println("Failed to cache result: ", err)
+		}
+	}
+
+	// A rejudge rebuilds the statistics once all of its submissions are judged again.
+	if ecr.ExecutionType == models.ExecutionTypeRejudge {
+		h.recordRejudgeResult(ctx, ecr.ID, verdict, status)
+		return
+	}
+
+	// Only the first verdict of a submission counts towards the statistics; system errors
+	// are not the user's fault and are left out.
+	if !submission.State.IsFinal() && state == models.SubmissionStateJudged {
+		if err := h.problemRepo.RecordJudgement(ctx, submission.ProblemID, submission.UserID, verdict == "Accepted"); err != nil {
+			log.Println("Failed to record judgement: ", err)
+			return
+		}
+		h.updateLeaderboard(ctx, submission.UserID)
+	}
+}
+
+// HandleExecutionEvent records lifecycle transitions reported by the execution service.
+// It is the callback of RedisService.StartStatusWorker.
+func (h *Handler) HandleExecutionEvent(ctx context.Context, event *models.ExecutionEvent) {
+	if event.ExecutionType != models.ExecutionTypeSubmission && event.ExecutionType != models.ExecutionTypeRejudge {
+		return
+	}
+	if err := h.submissionRepo.UpdateSubmissionState(ctx, event.ID, event.State, event.WorkerID, event.At); err != nil {
+		log.Println("Ignoring execution event: ", err)
+	}
+}
+
+func (h *Handler) handleValidationResult(ctx context.Context, ecr *models.ExecuteCodeResponse, status string) {
+	report := models.ProblemValidationReport{
+		ProblemID:       ecr.ID,
+		Passed:          status == "Accepted",
+		Message:         status,
+		TestCaseResults: ecr.TestCaseResults,
+		CreatedAt:       time.Now(),
+	}
+	if ecr.SystemError != "" {
+		report.Passed = false
+		report.Message = "System Error: " + ecr.SystemError
+	}
+	problem, err := h.problemRepo.GetProblemMetadata(ctx, ecr.ID, false)
+	if report.Passed && err == nil {
+		report.Calibration = h.calibrateTimeLimit(problem, ecr.TestCaseResults)
+	}
+	if report.Passed && len(ecr.SolutionResults) > 0 {
+		if h.timeLimits.Auto && report.Calibration != nil {
+			h.enforceTimeLimit(ecr.SolutionResults, problem.Solutions, report.Calibration.SuggestedLimitMS)
+		}
+		report.Solutions = checkSolutions(ecr.SolutionResults)
+		for _, s := range report.Solutions {
+			if !s.Passed {
+				report.Passed = false
+				report.Message = fmt.Sprintf("solution %q: %s", s.Name, s.Message)
+				break
+			}
+		}
+	}
+	if report.Passed {
+		if err := h.applyCalibratedLimit(ctx, ecr.ID, report.Calibration); err != nil {
+			log.Println("Failed to set the calibrated time limit: ", err)
+		}
+	}
+	h.problemRepo.SaveValidationReport(ctx, report)
+
+	next := models.ProblemStatusAwaitingReview
+	if !report.Passed {
+		next = models.ProblemStatusValidationFailed
+	}
+	if err := h.transitionProblem(ctx, ecr.ID, next, 0, report.Message); err != nil {
+		log.Println("Failed to record validation result: ", err)
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"online-judge/internal/models"
+	"online-judge/internal/repo"
+	"online-judge/internal/services"
+	"online-judge/internal/webhooks"
+)
+
+func TestHandleExecutionResultCountsFirstVerdict(t *testing.T) {
+	accepted := &models.ExecuteCodeResponse{TestCaseResults: []models.TestCaseResult{{Status: "Accepted"}}}
+	wrong := &models.ExecuteCodeResponse{TestCaseResults: []models.TestCaseResult{{Status: "Wrong Answer"}}}
+	failed := &models.ExecuteCodeResponse{SystemError: "worker crashed"}
+
+	tests := []struct {
+		name            string
+		results         []*models.ExecuteCodeResponse
+		total, accepted int
+	}{
+		{name: "accepted", results: []*models.ExecuteCodeResponse{accepted}, total: 1, accepted: 1},
+		{name: "wrong answer", results: []*models.ExecuteCodeResponse{wrong}, total: 1},
+		{name: "system error", results: []*models.ExecuteCodeResponse{failed}},
+		{name: "delivered twice", results: []*models.ExecuteCodeResponse{accepted, accepted}, total: 1, accepted: 1},
+		{name: "later verdict ignored", results: []*models.ExecuteCodeResponse{wrong, accepted}, total: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisService := services.NewRedisService("127.0.0.1:1")
+			defer redisService.Close()
+			h := &Handler{
+				submissionRepo: repo.NewSubmissionRepo(),
+				problemRepo:    repo.NewProblemRepo(),
+				webhooks:       webhooks.NewDispatcher(repo.NewWebhookRepo()),
+				redisService:   redisService,
+			}
+			// Redis is unavailable: a cancelled context makes its calls fail at once, while
+			// the in-memory repos ignore it.
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			id, err := h.submissionRepo.NewSubmission(ctx, models.SubmissionDB{UserID: 2, ProblemID: 1, LanguageID: 2}, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, res := range tt.results {
+				ecr := *res
+				ecr.ID, ecr.ExecutionType = id, models.ExecutionTypeSubmission
+				h.HandleExecutionResult(ctx, &ecr)
+			}
+
+			p, err := h.problemRepo.GetProblemMetadata(ctx, 1, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.TotalSubmissions != tt.total || p.AcceptedSubmissions != tt.accepted {
+				t.Errorf("counted %d submissions, %d accepted, want %d, %d", p.TotalSubmissions, p.AcceptedSubmissions, tt.total, tt.accepted)
+			}
+		})
+	}
+}
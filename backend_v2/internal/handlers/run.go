@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	maxCustomInputs     = 10
+	maxCustomInputBytes = 64 << 10 // 64 KiB
+)
+
+// RunCode executes the user's code on the problem's sample tests and on custom inputs
+// without recording a submission. Expected outputs for custom inputs are obtained by
+// first running the problem's reference solution on them; see HandleRunResult.
+func (h *Handler) RunCode(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		http.Error(w, "invalid token (userID)", http.StatusBadRequest)
+		return
+	}
+
+	isAdmin, _ := r.Context().Value(middleware.IsAdminKey).(bool)
+
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+	if !h.canAccessProblem(r.Context(), problemID, userID, isAdmin) {
+		http.Error(w, "problem not found", http.StatusNotFound)
+		return
+	}
+
+	var payload models.RunCodePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(payload.CustomInputs) > maxCustomInputs {
+		http.Error(w, fmt.Sprintf("at most %d custom inputs are allowed", maxCustomInputs), http.StatusBadRequest)
+		return
+	}
+	for _, input := range payload.CustomInputs {
+		if len(input) > maxCustomInputBytes {
+			http.Error(w, fmt.Sprintf("custom inputs must be at most %d bytes", maxCustomInputBytes), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// A retried request with the same Idempotency-Key gets the original run back.
+	idempotencyKey, replayID, ok := h.claimIdempotencyKey(w, r, "run", userID, payload)
+	if !ok {
+		return
+	}
+	if replayID != 0 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.RunCodeResponse{RunID: replayID})
+		return
+	}
+	var createdID int
+	defer func() {
+		h.finishIdempotencyKey(context.WithoutCancel(r.Context()), "run", userID, idempotencyKey, createdID)
+	}()
+
+	problem, err := h.playableProblem(r.Context(), problemID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	testCases, err := h.problemRepo.GetProblemTestCases(r.Context(), problemID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var samples []models.ProblemTestCase
+	for _, tc := range testCases {
+		if tc.IsSample {
+			samples = append(samples, tc)
+		}
+	}
+	if len(samples) == 0 && len(payload.CustomInputs) == 0 {
+		http.Error(w, "problem has no sample tests; provide custom inputs", http.StatusBadRequest)
+		return
+	}
+
+	run := models.RunDB{
+		UserID:         userID,
+		ProblemID:      problemID,
+		LanguageID:     payload.LanguageID,
+		Code:           payload.Code,
+		SampleTests:    samples,
+		CustomInputs:   payload.CustomInputs,
+		RuntimeLimitMS: h.timeLimits.ForLanguage(problem.RuntimeLimitMS, languageName(payload.LanguageID)),
+		MemoryLimitKB:  problem.MemoryLimitKB,
+		CheckerCode:    problem.CheckerCode,
+	}
+	runID, err := h.runRepo.NewRun(r.Context(), run)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	run.ID = runID
+
+	// TODO: obtain language from ID
+	language := "python"
+	job := models.ExecuteCodePayload{
+		ID:             runID,
+		UserID:         userID,
+		Code:           payload.Code,
+		TestCases:      samples,
+		RuntimeLimitMS: run.RuntimeLimitMS,
+		MemoryLimitKB:  problem.MemoryLimitKB,
+		CheckerCode:    problem.CheckerCode,
+		ExecutionType:  models.ExecutionTypeRun,
+	}
+	if len(payload.CustomInputs) > 0 {
+		job.Code = problem.SolutionCode
+		job.CheckerCode = "" // the reference output is what the checker compares against
+		job.RuntimeLimitMS = h.timeLimits.ForLanguage(problem.RuntimeLimitMS, languageName(problem.SolutionLanguageID))
+		job.TestCases = customTestCases(payload.CustomInputs, nil)
+		job.ExecutionType = models.ExecutionTypeRunReference
+	}
+
+	if err := h.redisService.ExecuteCode(r.Context(), language, job); err != nil {
+		http.Error(w, "error running the code: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	createdID = runID
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.RunCodeResponse{RunID: runID})
+}
+
+func (h *Handler) GetRunResultByID(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "runID"))
+	if err != nil {
+		http.Error(w, "Invalid run ID", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.runRepo.GetRun(r.Context(), id)
+	if err != nil || run.UserID != userID {
+		http.Error(w, "run not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// HandleRunResult processes execution results of Run requests. A reference result provides
+// the expected outputs of the custom inputs, after which the user's code is enqueued on the
+// sample and custom tests; a run result completes the run.
+func (h *Handler) HandleRunResult(ctx context.Context, ecr *models.ExecuteCodeResponse) {
+	run, err := h.runRepo.GetRun(ctx, ecr.ID)
+	if err != nil {
+		log.Println("Run result for unknown run: ", ecr.ID)
+		return
+	}
+
+	if ecr.ExecutionType == models.ExecutionTypeRun {
+		for i := range ecr.TestCaseResults {
+			ecr.TestCaseResults[i].IsCustom = i >= len(run.SampleTests)
+		}
+		status := ecr.Status
+		if ecr.SystemError != "" {
+			status = "System Error: " + ecr.SystemError
+		}
+		h.runRepo.CompleteRun(ctx, run.ID, status, ecr.TestCaseResults)
+		return
+	}
+
+	if len(ecr.TestCaseResults) != len(run.CustomInputs) {
+		h.runRepo.CompleteRun(ctx, run.ID, "Error: reference solution returned no result", nil)
+		return
+	}
+
+	// The reference run has no expected outputs, so its verdict is "Wrong Answer" whenever it
+	// printed something. Anything other than that or "Accepted" means the input is invalid.
+	var invalid []models.TestCaseResult
+	expected := make([]string, len(ecr.TestCaseResults))
+	for i, res := range ecr.TestCaseResults {
+		if res.Status != "Accepted" && res.Status != "Wrong Answer" {
+			invalid = append(invalid, models.TestCaseResult{
+				Input:    res.Input,
+				Status:   "Invalid Input: reference solution got " + res.Status,
+				IsCustom: true,
+			})
+			continue
+		}
+		expected[i] = res.Output
+	}
+	if len(invalid) > 0 {
+		h.runRepo.CompleteRun(ctx, run.ID, "Invalid Custom Input", invalid)
+		return
+	}
+
+	testCases := make([]models.ProblemTestCase, 0, len(run.SampleTests)+len(run.CustomInputs))
+	testCases = append(testCases, run.SampleTests...)
+	testCases = append(testCases, customTestCases(run.CustomInputs, expected)...)
+
+	// TODO: obtain language from ID
+	language := "python"
+	if err := h.redisService.ExecuteCode(ctx, language, models.ExecuteCodePayload{
+		ID:             run.ID,
+		UserID:         run.UserID,
+		Code:           run.Code,
+		TestCases:      testCases,
+		RuntimeLimitMS: run.RuntimeLimitMS,
+		MemoryLimitKB:  run.MemoryLimitKB,
+		CheckerCode:    run.CheckerCode,
+		ExecutionType:  models.ExecutionTypeRun,
+	}); err != nil {
+		h.runRepo.CompleteRun(ctx, run.ID, "Error: "+err.Error(), nil)
+	}
+}
+
+// customTestCases builds test cases from custom inputs; expected may be nil.
+func customTestCases(inputs, expected []string) []models.ProblemTestCase {
+	testCases := make([]models.ProblemTestCase, len(inputs))
+	for i, input := range inputs {
+		testCases[i] = models.ProblemTestCase{Input: input, Position: i + 1}
+		if expected != nil {
+			testCases[i].ExpectedOutput = expected[i]
+		}
+	}
+	return testCases
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"online-judge/internal/models"
+)
+
+// expectedVerdicts maps solution tags to the worker statuses they expect. Failing
+// solutions accept any status but Accepted.
+var expectedVerdicts = map[string]string{
+	models.SolutionTagWrongAnswer:  "Wrong Answer",
+	models.SolutionTagTimeLimit:    "TLE",
+	models.SolutionTagRuntimeError: "Error",
+}
+
+// checkSolutions compares the verdicts of the extra solutions of a problem with their
+// tags. Accepted solutions must pass every test; wrong solutions must be killed by at
+// least one test with the verdict they are tagged with, and a wrong solution that passes
+// means the tests are too weak.
+func checkSolutions(results []models.SolutionResult) []models.SolutionReport {
+	reports := make([]models.SolutionReport, 0, len(results))
+	for _, res := range results {
+		report := models.SolutionReport{
+			Name:     res.Name,
+			Tag:      res.Tag,
+			Verdict:  "Accepted",
+			KilledBy: []int{},
+		}
+		for i, tc := range res.TestCaseResults {
+			if tc.Status == "Accepted" {
+				continue
+			}
+			if report.Verdict == "Accepted" {
+				report.Verdict = tc.Status
+			}
+			if expected, ok := expectedVerdicts[res.Tag]; !ok || tc.Status == expected {
+				report.KilledBy = append(report.KilledBy, i+1)
+			}
+		}
+
+		switch {
+		case res.SystemError != "":
+			report.Verdict = "System Error"
+			report.Message = "could not be judged: " + res.SystemError
+		case res.Tag == models.SolutionTagAccepted:
+			report.Passed = report.Verdict == "Accepted"
+			if !report.Passed {
+				report.Message = "expected Accepted, got " + report.Verdict
+			}
+		case report.Verdict == "Accepted":
+			report.Message = "a " + res.Tag + " solution passed every test"
+		case len(report.KilledBy) == 0:
+			report.Message = fmt.Sprintf("expected %s, got %s", expectedVerdicts[res.Tag], report.Verdict)
+		default:
+			report.Passed = true
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// solutionLanguage is the language the worker runs extra solutions in.
+const solutionLanguage = "Python"
+
+// checkSolutionTags rejects extra solutions without code, in a language the worker cannot
+// run them in, or with a tag validation does not know how to check.
+func checkSolutionTags(problem *models.ProblemDB) error {
+	for i, s := range problem.Solutions {
+		if strings.TrimSpace(s.Code) == "" {
+			return fmt.Errorf("solution %d has no code", i+1)
+		}
+		if languageName(s.LanguageID) != solutionLanguage {
+			return fmt.Errorf("solution %d must be written in %s", i+1, solutionLanguage)
+		}
+		if _, ok := expectedVerdicts[s.Tag]; !ok && s.Tag != models.SolutionTagAccepted && s.Tag != models.SolutionTagFailing {
+			return fmt.Errorf("solution %d has an invalid tag %q", i+1, s.Tag)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"online-judge/internal/models"
+)
+
+func TestCheckSolutions(t *testing.T) {
+	results := func(statuses ...string) []models.TestCaseResult {
+		res := make([]models.TestCaseResult, len(statuses))
+		for i, s := range statuses {
+			res[i] = models.TestCaseResult{Status: s}
+		}
+		return res
+	}
+
+	tests := []struct {
+		name     string
+		result   models.SolutionResult
+		passed   bool
+		verdict  string
+		killedBy []int
+		message  string
+	}{
+		{
+			name:    "accepted passes",
+			result:  models.SolutionResult{Tag: models.SolutionTagAccepted, TestCaseResults: results("Accepted", "Accepted")},
+			passed:  true,
+			verdict: "Accepted",
+		},
+		{
+			name:     "accepted fails",
+			result:   models.SolutionResult{Tag: models.SolutionTagAccepted, TestCaseResults: results("Accepted", "TLE")},
+			verdict:  "TLE",
+			killedBy: []int{2},
+			message:  "expected Accepted, got TLE",
+		},
+		{
+			name:    "wrong answer survives",
+			result:  models.SolutionResult{Tag: models.SolutionTagWrongAnswer, TestCaseResults: results("Accepted")},
+			verdict: "Accepted",
+			message: "a wrong-answer solution passed every test",
+		},
+		{
+			name:     "wrong answer killed",
+			result:   models.SolutionResult{Tag: models.SolutionTagWrongAnswer, TestCaseResults: results("Accepted", "Wrong Answer", "TLE", "Wrong Answer")},
+			passed:   true,
+			verdict:  "Wrong Answer",
+			killedBy: []int{2, 4},
+		},
+		{
+			name:    "killed with another verdict",
+			result:  models.SolutionResult{Tag: models.SolutionTagTimeLimit, TestCaseResults: results("Wrong Answer")},
+			verdict: "Wrong Answer",
+			message: "expected TLE, got Wrong Answer",
+		},
+		{
+			name:     "failing killed by anything",
+			result:   models.SolutionResult{Tag: models.SolutionTagFailing, TestCaseResults: results("Accepted", "Error")},
+			passed:   true,
+			verdict:  "Error",
+			killedBy: []int{2},
+		},
+		{
+			name:    "system error",
+			result:  models.SolutionResult{Tag: models.SolutionTagWrongAnswer, SystemError: "checker failed: exit status 2"},
+			verdict: "System Error",
+			message: "could not be judged: checker failed: exit status 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := checkSolutions([]models.SolutionResult{tt.result})
+			if len(reports) != 1 {
+				t.Fatalf("got %d reports, want 1", len(reports))
+			}
+			r := reports[0]
+			if tt.killedBy == nil {
+				tt.killedBy = []int{}
+			}
+			if r.Passed != tt.passed || r.Verdict != tt.verdict || r.Message != tt.message || !reflect.DeepEqual(r.KilledBy, tt.killedBy) {
+				t.Errorf("report = %+v, want passed %v verdict %q killed by %v message %q",
+					r, tt.passed, tt.verdict, tt.killedBy, tt.message)
+			}
+		})
+	}
+}
+
+func TestCheckSolutionTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		solution models.ProblemSolution
+		wantErr  string
+	}{
+		{name: "valid", solution: models.ProblemSolution{LanguageID: 2, Code: "print()", Tag: models.SolutionTagWrongAnswer}},
+		{name: "no code", solution: models.ProblemSolution{LanguageID: 2, Code: " ", Tag: models.SolutionTagAccepted}, wantErr: "has no code"},
+		{name: "not python", solution: models.ProblemSolution{LanguageID: 1, Code: "package main", Tag: models.SolutionTagAccepted}, wantErr: "must be written in Python"},
+		{name: "main tag", solution: models.ProblemSolution{LanguageID: 2, Code: "print()", Tag: models.SolutionTagMain}, wantErr: "invalid tag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSolutionTags(&models.ProblemDB{Solutions: []models.ProblemSolution{tt.solution}})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"online-judge/internal/models"
+	"online-judge/internal/problempkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const maxTestArchiveBytes = 32 << 20 // 32 MiB
+
+func (h *Handler) GetProblemTestCases(w http.ResponseWriter, r *http.Request) {
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+
+	testCases, err := h.problemRepo.GetProblemTestCases(r.Context(), problemID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(testCases)
+}
+
+// AddProblemTestCases appends one or more test cases, given as a JSON array, to a problem.
+func (h *Handler) AddProblemTestCases(w http.ResponseWriter, r *http.Request) {
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+
+	var payload []models.ProblemTestCase
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(payload) == 0 {
+		http.Error(w, "at least one test case is required", http.StatusBadRequest)
+		return
+	}
+
+	added, err := h.problemRepo.AddProblemTestCases(r.Context(), problemID, payload, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(added)
+}
+
+func (h *Handler) UpdateProblemTestCase(w http.ResponseWriter, r *http.Request) {
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+
+	testCaseID, err := strconv.Atoi(chi.URLParam(r, "testCaseID"))
+	if err != nil {
+		http.Error(w, "Invalid test case ID", http.StatusBadRequest)
+		return
+	}
+
+	var payload models.ProblemTestCase
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	payload.ID = testCaseID
+
+	if err := h.problemRepo.UpdateProblemTestCase(r.Context(), problemID, payload); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+func (h *Handler) DeleteProblemTestCase(w http.ResponseWriter, r *http.Request) {
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+
+	testCaseID, err := strconv.Atoi(chi.URLParam(r, "testCaseID"))
+	if err != nil {
+		http.Error(w, "Invalid test case ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.problemRepo.DeleteProblemTestCase(r.Context(), problemID, testCaseID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+func (h *Handler) ReorderProblemTestCases(w http.ResponseWriter, r *http.Request) {
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+
+	var payload models.ReorderTestCasesPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.problemRepo.ReorderProblemTestCases(r.Context(), problemID, payload.IDs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// UploadProblemTestCases imports a zip archive of NN.in / NN.out pairs sent as the "file"
+// field of a multipart form. Tests are added in numeric order of NN.
+// Query parameters: samples=N marks the first N tests as samples,
+// replace=true drops the existing test cases of the problem first.
+func (h *Handler) UploadProblemTestCases(w http.ResponseWriter, r *http.Request) {
+	problemID, err := problemIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid problem ID", http.StatusBadRequest)
+		return
+	}
+
+	samples := 0
+	if v := r.URL.Query().Get("samples"); v != "" {
+		if samples, err = strconv.Atoi(v); err != nil || samples < 0 {
+			http.Error(w, "samples must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+	replace := r.URL.Query().Get("replace") == "true"
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxTestArchiveBytes)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "a zip archive is required in the \"file\" form field: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	archive, err := zip.NewReader(file, header.Size)
+	if err != nil {
+		http.Error(w, "invalid zip archive: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	testCases, err := problempkg.ReadTests(archive.File)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for i := range testCases {
+		testCases[i].IsSample = i < samples
+	}
+
+	added, err := h.problemRepo.AddProblemTestCases(r.Context(), problemID, testCases, replace)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(added)
+}
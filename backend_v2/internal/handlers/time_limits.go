@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	mockdata "online-judge/internal/mock_data"
+	"online-judge/internal/models"
+)
+
+// calibrateTimeLimit suggests a base time limit for a problem from the worst time of its
+// reference solution. It uses CPU time, which is what the worker enforces limits on. It
+// returns nil when nothing was measured.
+func (h *Handler) calibrateTimeLimit(problem *models.ProblemDB, results []models.TestCaseResult) *models.TimeCalibration {
+	worst, worstTest := -1, 0
+	for i, res := range results {
+		if res.CPUTimeMS > worst {
+			worst, worstTest = res.CPUTimeMS, i+1
+		}
+	}
+	if worst < 0 {
+		return nil
+	}
+
+	language := languageName(problem.SolutionLanguageID)
+	calibration := &models.TimeCalibration{
+		ReferenceLanguage: language,
+		WorstTimeMS:       worst,
+		WorstTest:         worstTest,
+		Factor:            h.timeLimits.Factor,
+		CurrentLimitMS:    problem.RuntimeLimitMS,
+		SuggestedLimitMS:  h.timeLimits.Suggest(worst, language),
+		LanguageLimitsMS:  make(map[string]int, len(mockdata.ProgrammingLanguages)),
+	}
+	limit := calibration.CurrentLimitMS
+	if h.timeLimits.Auto {
+		limit = calibration.SuggestedLimitMS
+	}
+	for _, lang := range mockdata.ProgrammingLanguages {
+		calibration.LanguageLimitsMS[strings.ToLower(lang.Name)] = h.timeLimits.ForLanguage(limit, lang.Name)
+	}
+	return calibration
+}
+
+// applyCalibratedLimit sets the suggested time limit on a problem that passed validation,
+// when time limits are set automatically.
+func (h *Handler) applyCalibratedLimit(ctx context.Context, problemID int, calibration *models.TimeCalibration) error {
+	if !h.timeLimits.Auto || calibration == nil {
+		return nil
+	}
+	if err := h.problemRepo.SetRuntimeLimit(ctx, problemID, calibration.SuggestedLimitMS); err != nil {
+		return err
+	}
+	calibration.Applied = true
+	return nil
+}
+
+// enforceTimeLimit marks tests that used more CPU time than the time limit of their
+// solution's language, derived from baseMS, as TLE. Validations timing the reference
+// solution run under a generous limit, so the extra solutions are judged against the
+// calibrated limit afterwards. Results are in the order of solutions.
+func (h *Handler) enforceTimeLimit(results []models.SolutionResult, solutions []models.ProblemSolution, baseMS int) {
+	for i := range results {
+		language := ""
+		if i < len(solutions) && solutions[i].Name == results[i].Name {
+			language = languageName(solutions[i].LanguageID)
+		}
+		limitMS := h.timeLimits.ForLanguage(baseMS, language)
+		for j := range results[i].TestCaseResults {
+			if tc := &results[i].TestCaseResults[j]; tc.CPUTimeMS > limitMS {
+				tc.Status = "TLE"
+			}
+		}
+	}
+}
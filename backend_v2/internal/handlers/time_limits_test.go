@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"testing"
+
+	"online-judge/internal/models"
+	"online-judge/internal/services"
+)
+
+func TestEnforceTimeLimit(t *testing.T) {
+	h := &Handler{timeLimits: services.TimeLimits{Multipliers: map[string]float64{"go": 1, "python": 3}}}
+	solutions := []models.ProblemSolution{
+		{Name: "fast", LanguageID: 1},
+		{Name: "slow", LanguageID: 2},
+	}
+	results := []models.SolutionResult{
+		{Name: "fast", TestCaseResults: []models.TestCaseResult{
+			{Status: "Accepted", CPUTimeMS: 900},
+			{Status: "Accepted", CPUTimeMS: 1100},
+		}},
+		{Name: "slow", TestCaseResults: []models.TestCaseResult{
+			{Status: "Accepted", CPUTimeMS: 2900},
+			{Status: "Wrong Answer", CPUTimeMS: 3100},
+		}},
+	}
+
+	h.enforceTimeLimit(results, solutions, 1000)
+
+	want := [][]string{{"Accepted", "TLE"}, {"Accepted", "TLE"}}
+	for i, res := range results {
+		for j, tc := range res.TestCaseResults {
+			if tc.Status != want[i][j] {
+				t.Errorf("%s test %d = %s, want %s", res.Name, j+1, tc.Status, want[i][j])
+			}
+		}
+	}
+}
+
+func TestCalibrateTimeLimitUsesCPUTime(t *testing.T) {
+	h := &Handler{timeLimits: services.TimeLimits{Factor: 2, Multipliers: map[string]float64{"python": 3}}}
+	problem := &models.ProblemDB{SolutionLanguageID: 2, RuntimeLimitMS: 1000}
+	results := []models.TestCaseResult{
+		{Status: "Accepted", RuntimeMS: 900, CPUTimeMS: 100},
+		{Status: "Accepted", RuntimeMS: 300, CPUTimeMS: 250},
+	}
+
+	calibration := h.calibrateTimeLimit(problem, results)
+	if calibration == nil {
+		t.Fatal("calibrateTimeLimit = nil")
+	}
+	if calibration.WorstTimeMS != 250 || calibration.WorstTest != 2 {
+		t.Errorf("worst = %d ms on test %d, want 250 ms on test 2", calibration.WorstTimeMS, calibration.WorstTest)
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/repo"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultTokenExpiryDays = 30
+	maxTokenExpiryDays     = 365
+)
+
+// CreateToken issues a personal access token. The response is the only place the token
+// value is shown.
+func (h *Handler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+	isAdmin, _ := r.Context().Value(middleware.IsAdminKey).(bool)
+
+	var payload models.PersonalAccessTokenPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	scopes, err := tokenScopes(payload.Scopes, isAdmin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(payload.Name)
+	if name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	days := payload.ExpiresInDays
+	if days == 0 {
+		days = defaultTokenExpiryDays
+	}
+	if days < 0 || days > maxTokenExpiryDays {
+		http.Error(w, "expires_in_days must be between 1 and 365", http.StatusBadRequest)
+		return
+	}
+
+	value, err := newPersonalToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	token := models.PersonalAccessTokenDB{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    value[:len(middleware.PersonalTokenPrefix)+6],
+		Hash:      repo.HashToken(value),
+		Scopes:    scopes,
+		ExpiresAt: time.Now().Add(time.Duration(days) * 24 * time.Hour),
+	}
+	token.ID, err = h.tokenRepo.CreateToken(r.Context(), token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	token.CreatedAt = time.Now()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.CreatedPersonalAccessToken{PersonalAccessTokenDB: token, Token: value})
+}
+
+func (h *Handler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	tokens, err := h.tokenRepo.ListTokens(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+func (h *Handler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "tokenID"))
+	if err != nil {
+		http.Error(w, "Invalid token ID", http.StatusBadRequest)
+		return
+	}
+	if err := h.tokenRepo.RevokeToken(r.Context(), userID, id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// AuthenticateToken implements middleware.Authenticator. A token only carries admin rights
+// while its owner is an administrator.
+func (h *Handler) AuthenticateToken(ctx context.Context, value string) (int, bool, []string, error) {
+	token, err := h.tokenRepo.Authenticate(ctx, value, time.Now())
+	if err != nil {
+		return 0, false, nil, err
+	}
+	user, err := h.userRepo.GetUserByID(ctx, token.UserID)
+	if err != nil || !user.IsActive {
+		return 0, false, nil, errors.New("token owner is not active")
+	}
+	return user.ID, user.IsAdmin && token.HasScope(models.TokenScopeAdminProblems), token.Scopes, nil
+}
+
+// IsAdmin implements middleware.Authenticator.
+func (h *Handler) IsAdmin(ctx context.Context, userID int) bool {
+	user, err := h.userRepo.GetUserByID(ctx, userID)
+	return err == nil && user.IsAdmin
+}
+
+// tokenScopes validates and de-duplicates requested scopes. admin:problems is reserved
+// for administrators.
+func tokenScopes(requested []string, isAdmin bool) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	known := make(map[string]bool, len(models.TokenScopes))
+	for _, s := range models.TokenScopes {
+		known[s] = true
+	}
+	scopes := make([]string, 0, len(requested))
+	seen := make(map[string]bool)
+	for _, s := range requested {
+		if !known[s] {
+			return nil, errors.New("unknown scope: " + s)
+		}
+		if s == models.TokenScopeAdminProblems && !isAdmin {
+			return nil, errors.New("only administrators can grant " + s)
+		}
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes, nil
+}
+
+func newPersonalToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return middleware.PersonalTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
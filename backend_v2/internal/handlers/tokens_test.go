@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"online-judge/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenScopes(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested []string
+		isAdmin   bool
+		want      []string
+		wantErr   string
+	}{
+		{name: "none", wantErr: "at least one scope"},
+		{name: "unknown", requested: []string{"read", "write"}, wantErr: "unknown scope: write"},
+		{
+			name:      "de-duplicated",
+			requested: []string{models.TokenScopeSubmit, models.TokenScopeRead, models.TokenScopeSubmit},
+			want:      []string{models.TokenScopeSubmit, models.TokenScopeRead},
+		},
+		{
+			name:      "admin scope for a user",
+			requested: []string{models.TokenScopeAdminProblems},
+			wantErr:   "only administrators",
+		},
+		{
+			name:      "admin scope for an admin",
+			requested: []string{models.TokenScopeAdminProblems},
+			isAdmin:   true,
+			want:      []string{models.TokenScopeAdminProblems},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokenScopes(tt.requested, tt.isAdmin)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenScopes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	mockdata "online-judge/internal/mock_data"
+	"online-judge/internal/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	dayFormat   = "2006-01-02"
+	heatmapDays = 365
+)
+
+// GetUserProfile handles GET /users/{username}
+func (h *Handler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
+	user, err := h.userRepo.GetUserByUsername(r.Context(), chi.URLParam(r, "username"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	submissions, err := h.submissionRepo.GetUserSubmissions(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	stats, err := h.problemRepo.GetUserStats(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	profile := h.buildUserProfile(r.Context(), submissions, time.Now())
+	profile.Username = user.Username
+	profile.UserStats = *stats
+	if profile.Rank, err = h.redisService.LeaderboardRank(r.Context(), user.ID); err != nil {
+		log.Println("Failed to read leaderboard rank: ", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// buildUserProfile aggregates a user's submissions, oldest first, into profile statistics.
+func (h *Handler) buildUserProfile(ctx context.Context, submissions []models.SubmissionDB, now time.Time) models.UserProfile {
+	profile := models.UserProfile{
+		SolvedByDifficulty: make(map[string]int),
+		SolvedByTag:        make(map[string]int),
+		Languages:          []models.LanguageStats{},
+		Heatmap:            make(map[string]int),
+	}
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, -heatmapDays+1)
+	activeDays := make(map[string]bool)
+	solved := make(map[int]bool)
+	languages := make(map[int]*models.LanguageStats)
+
+	for _, s := range submissions {
+		day := s.CreatedAt.UTC().Format(dayFormat)
+		activeDays[day] = true
+		if !s.CreatedAt.Before(since) {
+			profile.Heatmap[day]++
+		}
+
+		if s.State != models.SubmissionStateJudged {
+			continue
+		}
+		lang, ok := languages[s.LanguageID]
+		if !ok {
+			lang = &models.LanguageStats{LanguageID: s.LanguageID, Name: languageName(s.LanguageID)}
+			languages[s.LanguageID] = lang
+		}
+		lang.Submissions++
+		if s.Verdict != "Accepted" {
+			continue
+		}
+		lang.Accepted++
+
+		if solved[s.ProblemID] {
+			continue
+		}
+		solved[s.ProblemID] = true
+		if problem, err := h.problemRepo.GetProblemMetadata(ctx, s.ProblemID, false); err == nil {
+			profile.SolvedByDifficulty[problem.Difficulty.Name]++
+			for _, tag := range problem.Tags {
+				profile.SolvedByTag[tag.Name]++
+			}
+		}
+	}
+
+	for _, lang := range languages {
+		profile.Languages = append(profile.Languages, *lang)
+	}
+	sort.Slice(profile.Languages, func(i, j int) bool {
+		return profile.Languages[i].Submissions > profile.Languages[j].Submissions
+	})
+
+	profile.CurrentStreak, profile.LongestStreak = streaks(activeDays, today)
+	return profile
+}
+
+// streaks returns the number of consecutive active days ending today (or yesterday, when
+// the user has not submitted yet today) and the longest run of active days.
+func streaks(activeDays map[string]bool, today time.Time) (current, longest int) {
+	days := make([]time.Time, 0, len(activeDays))
+	for d := range activeDays {
+		if t, err := time.Parse(dayFormat, d); err == nil {
+			days = append(days, t)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	run := 0
+	for i, d := range days {
+		if i > 0 && d.Sub(days[i-1]) == 24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	day := today
+	if !activeDays[day.Format(dayFormat)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for activeDays[day.Format(dayFormat)] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return current, longest
+}
+
+func languageName(id int) string {
+	for _, lang := range mockdata.ProgrammingLanguages {
+		if lang.ID == id {
+			return lang.Name
+		}
+	}
+	return ""
+}
+
+// GetLeaderboard handles GET /leaderboard?page=1&page_size=20, ranking users by the number
+// of problems solved. The ranking is served from a Redis sorted set.
+func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	page, pageSize, err := parsePagination(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	offset := (page - 1) * pageSize
+	positions, total, err := h.redisService.LeaderboardPage(r.Context(), offset, pageSize)
+	if err == nil && total == 0 {
+		// The board is empty after a Redis restart; rebuild it from the judged submissions.
+		if err = h.rebuildLeaderboard(r.Context()); err == nil {
+			positions, total, err = h.redisService.LeaderboardPage(r.Context(), offset, pageSize)
+		}
+	}
+	if err != nil {
+		http.Error(w, "error reading the leaderboard: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	result := models.LeaderboardPage{
+		Entries:    []models.LeaderboardEntry{},
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	}
+	for _, p := range positions {
+		entry := models.LeaderboardEntry{Rank: p.Rank}
+		if stats, err := h.problemRepo.GetUserStats(r.Context(), p.UserID); err == nil {
+			entry.UserStats = *stats
+		}
+		if user, err := h.userRepo.GetUserByID(r.Context(), p.UserID); err == nil {
+			entry.Username = user.Username
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// updateLeaderboard refreshes a user's score after one of their submissions was judged.
+func (h *Handler) updateLeaderboard(ctx context.Context, userID int) {
+	stats, err := h.problemRepo.GetUserStats(ctx, userID)
+	if err == nil {
+		err = h.redisService.UpdateLeaderboard(ctx, userID, stats.SolvedCount)
+	}
+	if err != nil {
+		log.Println("Failed to update leaderboard: ", err)
+	}
+}
+
+// rebuildLeaderboard recomputes every score from the current statistics.
+func (h *Handler) rebuildLeaderboard(ctx context.Context) error {
+	stats, err := h.problemRepo.GetAllUserStats(ctx)
+	if err != nil {
+		return err
+	}
+	solved := make(map[int]int, len(stats))
+	for _, s := range stats {
+		solved[s.UserID] = s.SolvedCount
+	}
+	return h.redisService.RebuildLeaderboard(ctx, solved)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreaks(t *testing.T) {
+	today := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name             string
+		days             []string
+		current, longest int
+	}{
+		{name: "no activity"},
+		{name: "today only", days: []string{"2024-03-10"}, current: 1, longest: 1},
+		{name: "ends yesterday", days: []string{"2024-03-08", "2024-03-09"}, current: 2, longest: 2},
+		{name: "broken", days: []string{"2024-03-07", "2024-03-08"}, longest: 2},
+		{
+			name:    "longest in the past",
+			days:    []string{"2024-02-01", "2024-02-02", "2024-02-03", "2024-03-09", "2024-03-10"},
+			current: 2,
+			longest: 3,
+		},
+		{name: "across months", days: []string{"2024-02-28", "2024-02-29", "2024-03-01"}, longest: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active := make(map[string]bool)
+			for _, d := range tt.days {
+				active[d] = true
+			}
+			current, longest := streaks(active, today)
+			if current != tt.current || longest != tt.longest {
+				t.Errorf("streaks = %d, %d, want %d, %d", current, longest, tt.current, tt.longest)
+			}
+		})
+	}
+}
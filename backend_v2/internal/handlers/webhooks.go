@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+	"online-judge/internal/webhooks"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateWebhook registers a URL for a set of events. The response is the only place the
+// signing secret is shown.
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	var payload models.WebhookPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	webhook, err := buildWebhook(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = webhooks.NewSecret()
+	}
+	webhook.CreatedBy = userID
+
+	id, err := h.webhookRepo.CreateWebhook(r.Context(), webhook)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	webhook, _ = h.webhookRepo.GetWebhook(r.Context(), id)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// UpdateWebhook changes the URL, events or active flag of a webhook, and rotates the
+// secret when one is given.
+func (h *Handler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := webhookIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+
+	var payload models.WebhookPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	webhook, err := buildWebhook(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	webhook.ID = id
+
+	if err := h.webhookRepo.UpdateWebhook(r.Context(), webhook); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := webhookIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+	if err := h.webhookRepo.DeleteWebhook(r.Context(), id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.webhookRepo.ListWebhooks(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// ListWebhookDeliveries returns the delivery log of a webhook, newest first.
+func (h *Handler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := webhookIDFromURL(r)
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.webhookRepo.GetWebhook(r.Context(), id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	deliveries, err := h.webhookRepo.ListDeliveries(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+func buildWebhook(payload models.WebhookPayload) (models.WebhookDB, error) {
+	u, err := url.Parse(payload.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.WebhookDB{}, errors.New("url must be an absolute http(s) URL")
+	}
+	// Hostnames are checked again on every delivery, once resolved.
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); strings.EqualFold(host, "localhost") || (err == nil && webhooks.BlockedAddr(addr)) {
+		return models.WebhookDB{}, errors.New("url must point to a public address")
+	}
+	if len(payload.Events) == 0 {
+		return models.WebhookDB{}, errors.New("at least one event is required")
+	}
+
+	known := make(map[string]bool, len(models.WebhookEvents))
+	for _, e := range models.WebhookEvents {
+		known[e] = true
+	}
+	events := make([]string, 0, len(payload.Events))
+	seen := make(map[string]bool)
+	for _, e := range payload.Events {
+		if !known[e] {
+			return models.WebhookDB{}, errors.New("unknown event: " + e)
+		}
+		if !seen[e] {
+			seen[e] = true
+			events = append(events, e)
+		}
+	}
+
+	active := true
+	if payload.Active != nil {
+		active = *payload.Active
+	}
+	return models.WebhookDB{
+		URL:    payload.URL,
+		Events: events,
+		Secret: payload.Secret,
+		Active: active,
+	}, nil
+}
+
+func webhookIDFromURL(r *http.Request) (int, error) {
+	return strconv.Atoi(chi.URLParam(r, "webhookID"))
+}
+
+// transitionProblem changes the status of a problem and notifies webhooks when it becomes
+// active or fails validation.
+func (h *Handler) transitionProblem(ctx context.Context, problemID int, to models.ProblemStatus, actorID int, comment string) error {
+	if err := h.problemRepo.TransitionProblemStatus(ctx, problemID, to, actorID, comment); err != nil {
+		return err
+	}
+
+	var event string
+	switch to {
+	case models.ProblemStatusActive:
+		event = models.WebhookEventProblemActivated
+	case models.ProblemStatusValidationFailed:
+		event = models.WebhookEventProblemValidationFailed
+	default:
+		return nil
+	}
+
+	transitions, err := h.problemRepo.GetProblemTransitions(ctx, problemID)
+	if err != nil || len(transitions) == 0 {
+		log.Println("Failed to load transition for webhook: ", err)
+		return nil
+	}
+	h.webhooks.Emit(ctx, event, transitions[len(transitions)-1])
+	return nil
+}
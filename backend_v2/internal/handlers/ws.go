@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"online-judge/internal/middleware"
+	"online-judge/internal/models"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingPeriod     = wsPongWait * 9 / 10
+	wsMaxMessageSize = 4 << 10
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     checkOrigin,
+}
+
+// checkOrigin lets clients that authenticate with a bearer token connect from any origin.
+// Connections authenticated by the session cookie, which browsers attach whichever page
+// opens the socket, must come from the API's own origin.
+func checkOrigin(r *http.Request) bool {
+	if r.Header.Get("Authorization") != "" {
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// Not a browser, so no ambient cookie was sent on the user's behalf.
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// subscriptionMessage is sent by clients to choose which submissions to follow.
+type subscriptionMessage struct {
+	Action        string `json:"action"` // subscribe or unsubscribe
+	SubmissionIDs []int  `json:"submission_ids"`
+}
+
+// SubmissionUpdates upgrades to a WebSocket on which the user subscribes to their own
+// submissions, e.g. {"action":"subscribe","submission_ids":[42]}, and receives a
+// VerdictEvent as soon as each one is judged. Submissions that are already judged when
+// subscribed to are answered right away. Subscriptions to other users' submissions are
+// ignored.
+func (h *Handler) SubmissionUpdates(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int)
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied with an error.
+		return
+	}
+
+	client := h.hub.Register(userID)
+	go h.writeUpdates(conn, client.Send)
+
+	defer h.hub.Unregister(client)
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var msg subscriptionMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Println("WebSocket read error: ", err)
+			}
+			return
+		}
+
+		switch msg.Action {
+		case "subscribe":
+			for _, id := range msg.SubmissionIDs {
+				// Subscribe before reading the state, so a verdict published in between
+				// is delivered rather than lost. It may then arrive twice.
+				client.Subscribe(id)
+				submission, err := h.submissionRepo.GetSubmission(r.Context(), id, true)
+				if err != nil || submission.UserID != userID {
+					client.Unsubscribe(id)
+					continue
+				}
+				// Judged before the subscription: the published verdict has been missed.
+				if submission.State.IsFinal() {
+					event := models.NewVerdictEvent(*submission)
+					h.hub.SendTo(client, &event)
+				}
+			}
+		case "unsubscribe":
+			client.Unsubscribe(msg.SubmissionIDs...)
+		}
+	}
+}
+
+// writeUpdates writes queued messages and keep-alive pings until send is closed.
+func (h *Handler) writeUpdates(conn *websocket.Conn, send <-chan []byte) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-send:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// publishVerdict announces a finished submission to WebSocket subscribers on every
+// replica and, once judged, to the webhooks subscribed to submission.judged.
+func (h *Handler) publishVerdict(ctx context.Context, submissionID int) {
+	submission, err := h.submissionRepo.GetSubmission(ctx, submissionID, true)
+	if err != nil {
+		log.Println("Failed to publish verdict: ", err)
+		return
+	}
+	event := models.NewVerdictEvent(*submission)
+	if err := h.redisService.PublishVerdict(ctx, event); err != nil {
+		log.Println("Failed to publish verdict: ", err)
+	}
+	// Submissions the judge failed to process have no verdict to announce.
+	if submission.State == models.SubmissionStateJudged {
+		h.webhooks.Emit(ctx, models.WebhookEventSubmissionJudged, event)
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		origin        string
+		want          bool
+	}{
+		{name: "no origin", want: true},
+		{name: "same origin", origin: "https://judge.example.com", want: true},
+		{name: "same origin, other case", origin: "https://Judge.Example.com", want: true},
+		{name: "cross origin with cookie", origin: "https://evil.example.com", want: false},
+		{name: "cross origin with bearer token", authorization: "Bearer ojp_token", origin: "https://app.example.com", want: true},
+		{name: "malformed origin", origin: "://", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "http://judge.example.com/api/ws/submissions", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if got := checkOrigin(r); got != tt.want {
+				t.Errorf("checkOrigin = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
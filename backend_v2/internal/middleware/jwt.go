@@ -1,40 +1,138 @@
-package middleware
-
-import (
-	"context"
-	"net/http"
-	authmodule "online-judge/internal/auth_module"
-)
-
-type contextKey string
-
-const UserIDKey = contextKey("user_id")
-
-func JWTAuthMiddleware(auth *authmodule.JWTAuth) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// authHeader := r.Header.Get("Authorization")
-			// if authHeader == "" {
-			// 	http.Error(w, "Missing Authorization header", http.StatusUnauthorized)
-			// 	return
-			// }
-
-			// parts := strings.SplitN(authHeader, " ", 2)
-			// if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			// 	http.Error(w, "Invalid Authorization format", http.StatusUnauthorized)
-			// 	return
-			// }
-
-			// userID, err := auth.Validate(parts[1])
-			// if err != nil {
-			// 	http.Error(w, "Invalid or expired token: "+err.Error(), http.StatusUnauthorized)
-			// 	return
-			// }
-
-			userID := 1
-
-			ctx := context.WithValue(r.Context(), UserIDKey, userID)
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
-	}
-}
+package middleware
+
+import (
+	"context"
+	"net/http"
+	authmodule "online-judge/internal/auth_module"
+	"strconv"
+	"strings"
+)
+
+type contextKey string
+
+const UserIDKey = contextKey("user_id")
+const IsAdminKey = contextKey("is_admin")
+
+// ScopesKey holds the scopes of the personal access token a request was made with. It is
+// unset for JWT and cookie sessions, which are not restricted.
+const ScopesKey = contextKey("scopes")
+
+// PersonalTokenPrefix marks personal access tokens so they are not parsed as JWTs.
+const PersonalTokenPrefix = "ojp_"
+
+// Authenticator resolves the user behind a credential.
+type Authenticator interface {
+	// AuthenticateToken validates a personal access token.
+	AuthenticateToken(ctx context.Context, token string) (userID int, isAdmin bool, scopes []string, err error)
+	// IsAdmin reports whether the user authenticated by a JWT is an administrator.
+	IsAdmin(ctx context.Context, userID int) bool
+}
+
+// SessionCookie carries the JWT of a browser session.
+const SessionCookie = "auth_token"
+
+// JWTAuthMiddleware authenticates "Authorization: Bearer" credentials, which may be a JWT
+// or a personal access token, falling back to the session cookie. Requests without
+// credentials are rejected.
+func JWTAuthMiddleware(auth *authmodule.JWTAuth, users Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var token string
+			if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+				parts := strings.SplitN(authHeader, " ", 2)
+				if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+					http.Error(w, "Invalid Authorization format", http.StatusUnauthorized)
+					return
+				}
+				token = strings.TrimSpace(parts[1])
+			} else if cookie, err := r.Cookie(SessionCookie); err == nil {
+				token = cookie.Value
+			}
+			if token == "" {
+				http.Error(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+
+			var userID int
+			var isAdmin bool
+			var scopes []string
+			if strings.HasPrefix(token, PersonalTokenPrefix) {
+				var err error
+				userID, isAdmin, scopes, err = users.AuthenticateToken(r.Context(), token)
+				if err != nil {
+					http.Error(w, "Invalid or expired token: "+err.Error(), http.StatusUnauthorized)
+					return
+				}
+				if scopes == nil {
+					scopes = []string{}
+				}
+			} else {
+				id, err := auth.Validate(token)
+				if err == nil {
+					userID, err = strconv.Atoi(*id)
+				}
+				if err != nil {
+					http.Error(w, "Invalid or expired token: "+err.Error(), http.StatusUnauthorized)
+					return
+				}
+				isAdmin = users.IsAdmin(r.Context(), userID)
+			}
+
+			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx = context.WithValue(ctx, IsAdminKey, isAdmin)
+			if scopes != nil {
+				ctx = context.WithValue(ctx, ScopesKey, scopes)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// AdminOnly rejects requests from users that are not administrators.
+// It must be mounted after JWTAuthMiddleware.
+func AdminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isAdmin, _ := r.Context().Value(IsAdminKey).(bool)
+		if !isAdmin {
+			http.Error(w, "admin access required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireScope rejects personal access tokens that were not granted the scope. Sessions
+// pass through. It must be mounted after JWTAuthMiddleware.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, ok := r.Context().Value(ScopesKey).([]string)
+			if ok && !hasScope(scopes, scope) {
+				http.Error(w, "token is missing the "+scope+" scope", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SessionOnly rejects requests made with a personal access token, so that tokens cannot
+// be used to mint or revoke tokens.
+func SessionOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(ScopesKey).([]string); ok {
+			http.Error(w, "personal access tokens cannot manage tokens", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	authmodule "online-judge/internal/auth_module"
+	"testing"
+	"time"
+)
+
+type fakeUsers struct{}
+
+func (fakeUsers) AuthenticateToken(ctx context.Context, token string) (int, bool, []string, error) {
+	if token == PersonalTokenPrefix+"valid" {
+		return 7, false, []string{"read"}, nil
+	}
+	return 0, false, nil, errors.New("unknown token")
+}
+
+func (fakeUsers) IsAdmin(ctx context.Context, userID int) bool { return userID == 1 }
+
+func TestJWTAuthMiddleware(t *testing.T) {
+	auth := authmodule.NewJWTAuth([]byte("secret"), time.Hour)
+	adminJWT, err := auth.GetToken("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userJWT, err := auth.GetToken("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		cookie     string
+		wantStatus int
+		wantUser   int
+		wantAdmin  bool
+	}{
+		{name: "no credentials", wantStatus: http.StatusUnauthorized},
+		{name: "bad scheme", header: "Basic abc", wantStatus: http.StatusUnauthorized},
+		{name: "bad jwt", header: "Bearer abc", wantStatus: http.StatusUnauthorized},
+		{name: "bad personal token", header: "Bearer " + PersonalTokenPrefix + "x", wantStatus: http.StatusUnauthorized},
+		{name: "admin jwt", header: "Bearer " + adminJWT, wantStatus: http.StatusOK, wantUser: 1, wantAdmin: true},
+		{name: "user jwt", header: "Bearer " + userJWT, wantStatus: http.StatusOK, wantUser: 2},
+		{name: "session cookie", cookie: userJWT, wantStatus: http.StatusOK, wantUser: 2},
+		{name: "personal token", header: "Bearer " + PersonalTokenPrefix + "valid", wantStatus: http.StatusOK, wantUser: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUser int
+			var gotAdmin bool
+			h := JWTAuthMiddleware(auth, fakeUsers{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = r.Context().Value(UserIDKey).(int)
+				gotAdmin, _ = r.Context().Value(IsAdminKey).(bool)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/problems", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: SessionCookie, Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if gotUser != tt.wantUser || gotAdmin != tt.wantAdmin {
+				t.Errorf("user = %d admin = %v, want %d %v", gotUser, gotAdmin, tt.wantUser, tt.wantAdmin)
+			}
+		})
+	}
+}
@@ -1,139 +1,802 @@
-package models
-
-type basic struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
-}
-
-type Tag basic
-type Difficulty basic
-type ProgrammingLanguage basic
-
-type ProblemExamples struct {
-	ID             int    `json:"id"`
-	Input          string `json:"input"`
-	ExpectedOutput string `json:"expected_output"`
-	Explanation    string `json:"explanation"`
-}
-
-type ProblemTestCase struct {
-	ID             int    `json:"id"`
-	Input          string `json:"input"`
-	ExpectedOutput string `json:"expected_output"`
-}
-
-type ProblemInfo struct {
-	ID             int        `json:"id"`
-	Title          string     `json:"title"`
-	Slug           string     `json:"slug"`
-	Tags           []Tag      `json:"tags"`
-	Difficulty     Difficulty `json:"difficulty"`
-	AcceptanceRate float32    `json:"acceptance_rate"`
-	IsSolved       *bool      `json:"is_solved"`
-}
-
-type ProblemDetail struct {
-	ID             int               `json:"id"`
-	Title          string            `json:"title"`
-	Description    string            `json:"description"`
-	Constraints    []string          `json:"constraints"`
-	Slug           string            `json:"slug"`
-	Tags           []Tag             `json:"tags"`
-	Difficulty     Difficulty        `json:"difficulty"`
-	AcceptanceRate float32           `json:"acceptance_rate"`
-	IsSolved       *bool             `json:"is_solved"`
-	Examples       []ProblemExamples `json:"examples"`
-}
-
-type ProblemDB struct {
-	ID                 int               `json:"id"`
-	Title              string            `json:"title"`
-	Description        string            `json:"description"`
-	Constraints        []string          `json:"constraints"`
-	Slug               string            `json:"slug"`
-	Tags               []Tag             `json:"tags"`
-	Difficulty         Difficulty        `json:"difficulty"`
-	AcceptanceRate     float32           `json:"acceptance_rate"`
-	IsSolved           *bool             `json:"is_solved,omitempty"`
-	Examples           []ProblemExamples `json:"examples"`
-	SolutionLanguageID int               `json:"solution_language_id"`
-	SolutionCode       string            `json:"solution_code"`
-	Explaination       string            `json:"explaination"`
-	Status             string            `json:"status"` // Active, In Review, Rejected, Inactive
-	RuntimeLimitMS     int               `json:"runtime_limit_ms"`
-	MemoryLimitKB      int               `json:"memory_limit_kb"`
-}
-
-type SubmitCodePayload struct {
-	ProblemID  int    `json:"problem_id"`
-	LanguageID int    `json:"language_id"`
-	Code       string `json:"code"`
-}
-
-type SubmitCodeResponse struct {
-	SubmissionID int `json:"submission_id"`
-}
-
-type TestCaseResult struct {
-	ID             int    `json:"id"`
-	Input          string `json:"input"`
-	Output         string `json:"output"`
-	ExpectedOutput string `json:"expected_output"`
-	RuntimeMS      int    `json:"runtime_ms"`
-	MemoryKB       int    `json:"memory_kb"`
-	Status         string `json:"status"` // TLE, MLE, Acccepted, Wrong Answer, $Error.message
-}
-
-type ExecuteCodePayload struct {
-	ID int `json:"id"`
-	// LanguageID     int               `json:"language_id"`
-	Code           string            `json:"code"`
-	TestCases      []ProblemTestCase `json:"test_cases"`
-	RuntimeLimitMS int               `json:"runtime_limit_ms"`
-	MemoryLimitKB  int               `json:"memory_limit_kb"`
-	ExecutionType  string            `json:"execution_type"` // Run, Submit, Validation
-}
-
-type ExecuteCodeResponse struct {
-	ID              int              `json:"id"`
-	Status          string           `json:"status"` // TLE, MLE, Acccepted, Wrong Answer, $Error.message
-	TestCaseResults []TestCaseResult `json:"test_case_results"`
-	ExecutionType   string           `json:"execution_type"` // Run, Submit, Validation
-}
-
-type SubmissionDB struct {
-	ID        int    `json:"id"`
-	Status    string `json:"status"`
-	UserID    int    `json:"user_id"`
-	ProblemID int    `json:"problem_id"`
-	RuntimeMS int    `json:"runtime_ms"`
-	MemoryKB  int    `json:"memory_kb"`
-}
-
-type UserDB struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	IsAdmin  bool   `json:"is_admin"`
-	IsActive bool   `json:"is_active"`
-}
-
-type UserInfo struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-}
-
-type SignupPayload struct {
-	Username        string `json:"username"`
-	Email           string `json:"email"`
-	Password        string `json:"password"`
-	ConfirmPassword string `json:"confirm_password"`
-}
-
-type LoginPayload struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
-}
-
-type AuthResponse UserInfo
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type basic struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type Tag basic
+type Difficulty basic
+type ProgrammingLanguage basic
+
+type ProblemExamples struct {
+	ID             int    `json:"id"`
+	Input          string `json:"input"`
+	ExpectedOutput string `json:"expected_output"`
+	Explanation    string `json:"explanation"`
+}
+
+type ProblemTestCase struct {
+	ID             int    `json:"id"`
+	ProblemID      int    `json:"problem_id,omitempty"`
+	Input          string `json:"input"`
+	ExpectedOutput string `json:"expected_output"`
+	IsSample       bool   `json:"is_sample"`           // sample tests are shown to users, the rest are hidden
+	Position       int    `json:"position"`            // 1-based execution order within the problem
+	Generator      string `json:"generator,omitempty"` // the test script line that produced the test, empty if written by hand
+}
+
+// ProblemGenerator is a Python program that prints a test input for the arguments it is
+// run with, as listed in ProblemDB.TestScript.
+type ProblemGenerator struct {
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+type ReorderTestCasesPayload struct {
+	IDs []int `json:"ids"` // every test case ID of the problem, in the new order
+}
+
+type ProblemInfo struct {
+	ID             int        `json:"id"`
+	Title          string     `json:"title"`
+	Slug           string     `json:"slug"`
+	Tags           []Tag      `json:"tags"`
+	Difficulty     Difficulty `json:"difficulty"`
+	AcceptanceRate float32    `json:"acceptance_rate"`
+	IsSolved       bool       `json:"is_solved"` // for the requesting user
+}
+
+type ProblemListQuery struct {
+	Difficulty  string   // difficulty name, matched case-insensitively
+	Tags        []string // tag names, matched case-insensitively
+	MatchAllTag bool     // require every tag instead of any of them
+	Solved      *bool    // filter on whether the caller has solved the problem
+	UserID      int      // the caller, used for Solved and IsSolved
+	Search      string   // substring of the title, case-insensitive
+	SortBy      string   // id, acceptance, difficulty
+	Descending  bool
+	Page        int
+	PageSize    int
+	HiddenIDs   map[int]bool // problems of contests that have not ended, left out for non-admins
+}
+
+type ProblemListPage struct {
+	Problems   []ProblemInfo `json:"problems"`
+	Total      int           `json:"total"`
+	Page       int           `json:"page"`
+	PageSize   int           `json:"page_size"`
+	TotalPages int           `json:"total_pages"`
+}
+
+type ProblemSearchHit struct {
+	ProblemInfo
+	Rank           float64 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"` // description fragment with matches wrapped in <mark>
+}
+
+type ProblemSearchPage struct {
+	Query      string             `json:"query"`
+	Hits       []ProblemSearchHit `json:"hits"`
+	Total      int                `json:"total"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"page_size"`
+	TotalPages int                `json:"total_pages"`
+}
+
+type ProblemDetail struct {
+	ID                  int               `json:"id"`
+	Title               string            `json:"title"`
+	Description         string            `json:"description"`
+	Constraints         []string          `json:"constraints"`
+	Slug                string            `json:"slug"`
+	Tags                []Tag             `json:"tags"`
+	Difficulty          Difficulty        `json:"difficulty"`
+	AcceptanceRate      float32           `json:"acceptance_rate"`
+	TotalSubmissions    int               `json:"total_submissions"`
+	AcceptedSubmissions int               `json:"accepted_submissions"`
+	SolvedCount         int               `json:"solved_count"`
+	IsSolved            bool              `json:"is_solved"` // for the requesting user
+	Examples            []ProblemExamples `json:"examples"`
+}
+
+type ProblemDB struct {
+	ID                 int                `json:"id"`
+	Title              string             `json:"title"`
+	Description        string             `json:"description"`
+	Constraints        []string           `json:"constraints"`
+	Slug               string             `json:"slug"`
+	Tags               []Tag              `json:"tags"`
+	Difficulty         Difficulty         `json:"difficulty"`
+	AcceptanceRate     float32            `json:"acceptance_rate"` // computed from the submission counters below
+	Examples           []ProblemExamples  `json:"examples"`
+	SolutionLanguageID int                `json:"solution_language_id"`
+	SolutionCode       string             `json:"solution_code"`
+	Explaination       string             `json:"explaination"`
+	Status             ProblemStatus      `json:"status"`
+	RuntimeLimitMS     int                `json:"runtime_limit_ms"`
+	MemoryLimitKB      int                `json:"memory_limit_kb"`
+	CheckerLanguageID  int                `json:"checker_language_id,omitempty"`
+	CheckerCode        string             `json:"checker_code,omitempty"` // compares outputs instead of an exact match
+	Solutions          []ProblemSolution  `json:"solutions,omitempty"`    // besides the reference solution
+	Generators         []ProblemGenerator `json:"generators,omitempty"`
+	TestScript         []string           `json:"test_script,omitempty"`    // "generator arg..." per generated test
+	ValidatorCode      string             `json:"validator_code,omitempty"` // Python program that exits 0 for a valid input on stdin
+
+	// Judging statistics, maintained by ProblemRepo.RecordJudgement.
+	TotalSubmissions    int `json:"total_submissions"`
+	AcceptedSubmissions int `json:"accepted_submissions"`
+	SolvedCount         int `json:"solved_count"` // distinct users with an accepted submission
+}
+
+// Tags of the solutions kept with a problem, following Polygon.
+const (
+	SolutionTagMain         = "main" // the reference solution, stored as ProblemDB.SolutionCode
+	SolutionTagAccepted     = "accepted"
+	SolutionTagWrongAnswer  = "wrong-answer"
+	SolutionTagTimeLimit    = "time-limit-exceeded"
+	SolutionTagRuntimeError = "runtime-error"
+	SolutionTagFailing      = "failing" // any verdict but Accepted
+)
+
+var SolutionTags = []string{
+	SolutionTagMain,
+	SolutionTagAccepted,
+	SolutionTagWrongAnswer,
+	SolutionTagTimeLimit,
+	SolutionTagRuntimeError,
+	SolutionTagFailing,
+}
+
+// ProblemSolution is an extra solution kept with a problem, tagged with the verdict it
+// is expected to get.
+type ProblemSolution struct {
+	Name       string `json:"name"`
+	LanguageID int    `json:"language_id"`
+	Code       string `json:"code"`
+	Tag        string `json:"tag"`
+}
+
+// UserStats are the judging statistics of a user, maintained by ProblemRepo.RecordJudgement.
+type UserStats struct {
+	UserID              int `json:"user_id"`
+	TotalSubmissions    int `json:"total_submissions"`
+	AcceptedSubmissions int `json:"accepted_submissions"`
+	SolvedCount         int `json:"solved_count"`
+}
+
+type LanguageStats struct {
+	LanguageID  int    `json:"language_id"`
+	Name        string `json:"name"`
+	Submissions int    `json:"submissions"`
+	Accepted    int    `json:"accepted"`
+}
+
+// UserProfile is the public page of a user, computed from their submissions.
+type UserProfile struct {
+	Username           string          `json:"username"`
+	UserStats                          // counts of judged submissions
+	Rank               int             `json:"rank,omitempty"` // position on the global leaderboard, 0 when unranked
+	SolvedByDifficulty map[string]int  `json:"solved_by_difficulty"`
+	SolvedByTag        map[string]int  `json:"solved_by_tag"`
+	Languages          []LanguageStats `json:"languages"`
+	Heatmap            map[string]int  `json:"heatmap"` // UTC day (2006-01-02) -> submissions, over the last year
+	CurrentStreak      int             `json:"current_streak"`
+	LongestStreak      int             `json:"longest_streak"`
+}
+
+type LeaderboardEntry struct {
+	Rank     int    `json:"rank"`
+	Username string `json:"username"`
+	UserStats
+}
+
+type LeaderboardPage struct {
+	Entries    []LeaderboardEntry `json:"entries"`
+	Total      int                `json:"total"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"page_size"`
+	TotalPages int                `json:"total_pages"`
+}
+
+type ProblemStatus string
+
+const (
+	ProblemStatusDraft            ProblemStatus = "Draft"
+	ProblemStatusValidating       ProblemStatus = "Validating"
+	ProblemStatusValidationFailed ProblemStatus = "Validation Failed"
+	ProblemStatusAwaitingReview   ProblemStatus = "Awaiting Review"
+	ProblemStatusActive           ProblemStatus = "Active"
+	ProblemStatusRejected         ProblemStatus = "Rejected"
+	ProblemStatusInactive         ProblemStatus = "Inactive"
+)
+
+// problemTransitions lists, for every status, the statuses a problem may move to next.
+// Editing a problem always sends it back to Draft so it is validated and reviewed again.
+var problemTransitions = map[ProblemStatus][]ProblemStatus{
+	ProblemStatusDraft:            {ProblemStatusValidating},
+	ProblemStatusValidating:       {ProblemStatusValidationFailed, ProblemStatusAwaitingReview},
+	ProblemStatusValidationFailed: {ProblemStatusDraft},
+	ProblemStatusAwaitingReview:   {ProblemStatusActive, ProblemStatusRejected, ProblemStatusDraft},
+	ProblemStatusActive:           {ProblemStatusInactive, ProblemStatusDraft},
+	ProblemStatusRejected:         {ProblemStatusDraft},
+	ProblemStatusInactive:         {ProblemStatusActive, ProblemStatusDraft},
+}
+
+// CanTransitionTo reports whether a problem in status s may move to next.
+func (s ProblemStatus) CanTransitionTo(next ProblemStatus) bool {
+	for _, allowed := range problemTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+type ProblemStatusTransition struct {
+	ID        int           `json:"id"`
+	ProblemID int           `json:"problem_id"`
+	From      ProblemStatus `json:"from"`
+	To        ProblemStatus `json:"to"`
+	ActorID   int           `json:"actor_id"` // 0 when the transition was made by the system
+	Comment   string        `json:"comment"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type ProblemValidationReport struct {
+	ProblemID       int              `json:"problem_id"`
+	Passed          bool             `json:"passed"`
+	Message         string           `json:"message"`
+	TestCaseResults []TestCaseResult `json:"test_case_results"`
+	Solutions       []SolutionReport `json:"solutions,omitempty"`
+	Calibration     *TimeCalibration `json:"calibration,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+}
+
+// TimeCalibration is the time limit suggested by a validation: the worst time of the
+// reference solution times a factor, converted to the base limit of the problem.
+type TimeCalibration struct {
+	ReferenceLanguage string         `json:"reference_language"`
+	WorstTimeMS       int            `json:"worst_time_ms"` // CPU time
+	WorstTest         int            `json:"worst_test"`    // 1-based
+	Factor            float64        `json:"factor"`
+	CurrentLimitMS    int            `json:"current_limit_ms"`
+	SuggestedLimitMS  int            `json:"suggested_limit_ms"`
+	Applied           bool           `json:"applied"`            // the suggested limit replaced the current one
+	LanguageLimitsMS  map[string]int `json:"language_limits_ms"` // effective limit per language
+}
+
+// SolutionReport tells whether an extra solution of a problem got the verdict its tag
+// expects, and which tests killed it.
+type SolutionReport struct {
+	Name     string `json:"name"`
+	Tag      string `json:"tag"`
+	Verdict  string `json:"verdict"`   // Accepted or the status of the first failing test
+	Passed   bool   `json:"passed"`    // the verdict matches the tag
+	KilledBy []int  `json:"killed_by"` // positions of the tests it failed with the expected verdict
+	Message  string `json:"message,omitempty"`
+}
+
+// SolutionResult is the outcome of an extra solution, run by the worker during validation.
+type SolutionResult struct {
+	Name            string           `json:"name"`
+	Tag             string           `json:"tag"`
+	Status          string           `json:"status"`
+	TestCaseResults []TestCaseResult `json:"test_case_results"`
+	SystemError     string           `json:"system_error,omitempty"` // the solution could not be judged
+}
+
+type ReviewProblemPayload struct {
+	Comment string `json:"comment"`
+}
+
+type SubmitCodePayload struct {
+	ProblemID  int    `json:"problem_id"`
+	LanguageID int    `json:"language_id"`
+	Code       string `json:"code"`
+	ContestID  int    `json:"contest_id,omitempty"` // required for problems of a contest that has not ended
+}
+
+type SubmitCodeResponse struct {
+	SubmissionID         int `json:"submission_id"`
+	QueuePosition        int `json:"queue_position"`         // 1 is next in line, among submissions
+	EstimatedWaitSeconds int `json:"estimated_wait_seconds"` // until judging starts
+}
+
+type TestCaseResult struct {
+	ID             int    `json:"id"`
+	Input          string `json:"input"`
+	Output         string `json:"output"`
+	ExpectedOutput string `json:"expected_output"`
+	RuntimeMS      int    `json:"runtime_ms"`
+	CPUTimeMS      int    `json:"cpu_time_ms,omitempty"` // last sampled when the process was killed
+	MemoryKB       int    `json:"memory_kb"`
+	Status         string `json:"status"`              // TLE, MLE, Acccepted, Wrong Answer, $Error.message
+	IsCustom       bool   `json:"is_custom,omitempty"` // set on Run results for user-supplied inputs
+	IsSample       bool   `json:"is_sample"`
+	Redacted       bool   `json:"redacted,omitempty"`  // input and outputs of a hidden test were removed
+	Generator      string `json:"generator,omitempty"` // set on generation results
+}
+
+// Redact removes the input and outputs of a hidden test, keeping its verdict and resource usage.
+func (t TestCaseResult) Redact() TestCaseResult {
+	if t.IsSample || t.IsCustom {
+		return t
+	}
+	t.Input, t.Output, t.ExpectedOutput = "", "", ""
+	t.Redacted = true
+	return t
+}
+
+// Execution types tag jobs sent to the execution service so results can be routed back.
+const (
+	ExecutionTypeSubmission   = "submission"
+	ExecutionTypeValidation   = "validation"
+	ExecutionTypeRun          = "run"           // user code on sample and custom tests
+	ExecutionTypeRunReference = "run_reference" // reference solution on custom inputs, to obtain their expected outputs
+	ExecutionTypeRejudge      = "rejudge"       // an already judged submission, run again at low priority
+	ExecutionTypeGeneration   = "generation"    // generate and validate the tests of a problem before its validation
+)
+
+type RunCodePayload struct {
+	LanguageID   int      `json:"language_id"`
+	Code         string   `json:"code"`
+	CustomInputs []string `json:"custom_inputs"`
+}
+
+type RunCodeResponse struct {
+	RunID int `json:"run_id"`
+}
+
+type RunDB struct {
+	ID              int               `json:"id"`
+	UserID          int               `json:"user_id"`
+	ProblemID       int               `json:"problem_id"`
+	LanguageID      int               `json:"language_id"`
+	Code            string            `json:"-"`
+	Status          string            `json:"status"` // pending until the user's code has been executed
+	SampleTests     []ProblemTestCase `json:"-"`
+	CustomInputs    []string          `json:"-"`
+	RuntimeLimitMS  int               `json:"-"`
+	MemoryLimitKB   int               `json:"-"`
+	CheckerCode     string            `json:"-"`
+	TestCaseResults []TestCaseResult  `json:"test_case_results"`
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+type ExecuteCodePayload struct {
+	ID int `json:"id"`
+	// LanguageID     int               `json:"language_id"`
+	UserID         int                `json:"user_id"` // jobs are interleaved fairly across users
+	Code           string             `json:"code"`
+	TestCases      []ProblemTestCase  `json:"test_cases"`
+	RuntimeLimitMS int                `json:"runtime_limit_ms"`
+	MemoryLimitKB  int                `json:"memory_limit_kb"`
+	CheckerCode    string             `json:"checker_code,omitempty"`   // run as: checker input output answer
+	Generators     []ProblemGenerator `json:"generators,omitempty"`     // generation jobs only
+	TestScript     []string           `json:"test_script,omitempty"`    // generation jobs only
+	ValidatorCode  string             `json:"validator_code,omitempty"` // generation jobs only
+	Solutions      []ProblemSolution  `json:"solutions,omitempty"`      // validation jobs only, run after the code
+	ExecutionType  string             `json:"execution_type"`           // one of the ExecutionType constants
+}
+
+type ExecuteCodeResponse struct {
+	ID              int              `json:"id"`
+	Status          string           `json:"status"` // TLE, MLE, Acccepted, Wrong Answer, $Error.message
+	TestCaseResults []TestCaseResult `json:"test_case_results"`
+	ExecutionType   string           `json:"execution_type"`             // one of the ExecutionType constants
+	SystemError     string           `json:"system_error,omitempty"`     // the job could not be judged, not the code's fault
+	SolutionResults []SolutionResult `json:"solution_results,omitempty"` // validation jobs only
+	WorkerID        string           `json:"worker_id"`
+	StartedAt       time.Time        `json:"started_at"`
+	FinishedAt      time.Time        `json:"finished_at"`
+}
+
+// ExecutionEvent is reported by the execution service when a job changes lifecycle state.
+type ExecutionEvent struct {
+	ID            int             `json:"id"`
+	ExecutionType string          `json:"execution_type"`
+	State         SubmissionState `json:"state"`
+	WorkerID      string          `json:"worker_id"`
+	At            time.Time       `json:"at"`
+}
+
+type SubmissionState string
+
+const (
+	SubmissionStateQueued    SubmissionState = "Queued"
+	SubmissionStateCompiling SubmissionState = "Compiling"
+	SubmissionStateRunning   SubmissionState = "Running"
+	SubmissionStateJudged    SubmissionState = "Judged"
+	SubmissionStateFailed    SubmissionState = "Failed" // the judge could not process the submission
+)
+
+// submissionTransitions lists, for every state, the states a submission may move to next.
+// Worker events and results travel on separate queues, so states may be skipped.
+var submissionTransitions = map[SubmissionState][]SubmissionState{
+	SubmissionStateQueued:    {SubmissionStateCompiling, SubmissionStateRunning, SubmissionStateJudged, SubmissionStateFailed},
+	SubmissionStateCompiling: {SubmissionStateRunning, SubmissionStateJudged, SubmissionStateFailed},
+	SubmissionStateRunning:   {SubmissionStateJudged, SubmissionStateFailed},
+	SubmissionStateJudged:    {SubmissionStateQueued}, // rejudge
+	SubmissionStateFailed:    {SubmissionStateQueued}, // rejudge
+}
+
+// CanTransitionTo reports whether a submission in state s may move to next.
+func (s SubmissionState) CanTransitionTo(next SubmissionState) bool {
+	for _, allowed := range submissionTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether no more worker updates are expected in state s.
+func (s SubmissionState) IsFinal() bool {
+	return s == SubmissionStateJudged || s == SubmissionStateFailed
+}
+
+type SubmissionDB struct {
+	ID              int              `json:"id"`
+	Status          string           `json:"status"`
+	State           SubmissionState  `json:"state"`
+	Verdict         string           `json:"verdict"` // Accepted or the status of the first failing test, empty while pending
+	UserID          int              `json:"user_id"`
+	ProblemID       int              `json:"problem_id"`
+	LanguageID      int              `json:"language_id"`
+	Code            string           `json:"-"` // served by GET /submissions/{id}/code
+	RuntimeMS       int              `json:"runtime_ms"`
+	MemoryKB        int              `json:"memory_kb"`
+	TestCaseResults []TestCaseResult `json:"-"`                     // served by GET /submissions/{id}/tests
+	FailedTest      *TestCaseResult  `json:"failed_test,omitempty"` // first failing test, only when it is a sample
+	CreatedAt       time.Time        `json:"created_at"`
+	JudgedAt        *time.Time       `json:"judged_at"`
+	QueuedAt        *time.Time       `json:"queued_at"`
+	StartedAt       *time.Time       `json:"started_at"`
+	FinishedAt      *time.Time       `json:"finished_at"`
+	WorkerID        string           `json:"worker_id,omitempty"`
+	ResultCacheKey  string           `json:"-"` // hash of the judge job, see handlers.resultCacheHash
+	ContestID       int              `json:"contest_id,omitempty"`
+}
+
+// VerdictEvent is pushed to the owner of a submission once it has been judged.
+type VerdictEvent struct {
+	SubmissionID int             `json:"submission_id"`
+	UserID       int             `json:"user_id"`
+	ProblemID    int             `json:"problem_id"`
+	ContestID    int             `json:"contest_id,omitempty"`
+	State        SubmissionState `json:"state"`
+	Status       string          `json:"status"`
+	Verdict      string          `json:"verdict"`
+	RuntimeMS    int             `json:"runtime_ms"`
+	MemoryKB     int             `json:"memory_kb"`
+	JudgedAt     *time.Time      `json:"judged_at"`
+}
+
+// NewVerdictEvent returns the event announcing the current verdict of a submission.
+func NewVerdictEvent(s SubmissionDB) VerdictEvent {
+	return VerdictEvent{
+		SubmissionID: s.ID,
+		UserID:       s.UserID,
+		ProblemID:    s.ProblemID,
+		ContestID:    s.ContestID,
+		State:        s.State,
+		Status:       s.Status,
+		Verdict:      s.Verdict,
+		RuntimeMS:    s.RuntimeMS,
+		MemoryKB:     s.MemoryKB,
+		JudgedAt:     s.JudgedAt,
+	}
+}
+
+// SubmissionJudgement is the outcome of judging a submission.
+type SubmissionJudgement struct {
+	State           SubmissionState // Judged, or Failed when the judge could not process it
+	Status          string
+	Verdict         string
+	RuntimeMS       int
+	MemoryKB        int
+	TestCaseResults []TestCaseResult
+	FailedTest      *TestCaseResult
+	WorkerID        string
+	StartedAt       time.Time
+	FinishedAt      time.Time
+}
+
+type DurationStats struct {
+	Count int     `json:"count"`
+	P50MS float64 `json:"p50_ms"`
+	P95MS float64 `json:"p95_ms"`
+	MaxMS float64 `json:"max_ms"`
+}
+
+// QueueMetrics summarise how submissions flowed through the judge over a time window.
+type QueueMetrics struct {
+	WindowSeconds int                     `json:"window_seconds"`
+	States        map[SubmissionState]int `json:"states"`         // current state of the submissions queued in the window
+	QueueWait     DurationStats           `json:"queue_wait"`     // queued_at -> started_at
+	JudgeDuration DurationStats           `json:"judge_duration"` // started_at -> finished_at
+	LaneDepths    map[string]int64        `json:"lane_depths"`    // jobs waiting on each priority lane
+}
+
+// RejudgeFilter selects the judged submissions to rejudge. Zero fields match everything,
+// but at least one of them must be set.
+type RejudgeFilter struct {
+	SubmissionID int        `json:"submission_id,omitempty"`
+	ProblemID    int        `json:"problem_id,omitempty"`
+	From         *time.Time `json:"from,omitempty"` // submissions created at or after From
+	To           *time.Time `json:"to,omitempty"`   // submissions created before To
+}
+
+// Matches reports whether a submission is selected by the filter.
+func (f RejudgeFilter) Matches(s SubmissionDB) bool {
+	return (f.SubmissionID == 0 || s.ID == f.SubmissionID) &&
+		(f.ProblemID == 0 || s.ProblemID == f.ProblemID) &&
+		(f.From == nil || !s.CreatedAt.Before(*f.From)) &&
+		(f.To == nil || s.CreatedAt.Before(*f.To))
+}
+
+// VerdictChange records a submission whose verdict differs after a rejudge.
+type VerdictChange struct {
+	SubmissionID int    `json:"submission_id"`
+	UserID       int    `json:"user_id"`
+	ProblemID    int    `json:"problem_id"`
+	OldVerdict   string `json:"old_verdict"`
+	NewVerdict   string `json:"new_verdict"`
+	OldStatus    string `json:"old_status"`
+	NewStatus    string `json:"new_status"`
+}
+
+type RejudgeDB struct {
+	ID            int                  `json:"id"`
+	ActorID       int                  `json:"actor_id"`
+	Filter        RejudgeFilter        `json:"filter"`
+	SubmissionIDs []int                `json:"submission_ids"`
+	Pending       int                  `json:"pending"` // submissions still waiting for their new verdict
+	Changes       []VerdictChange      `json:"changes"`
+	Previous      map[int]SubmissionDB `json:"-"` // submission ID -> submission before the rejudge
+	CreatedAt     time.Time            `json:"created_at"`
+	CompletedAt   *time.Time           `json:"completed_at"`
+}
+
+type SubmissionListQuery struct {
+	UserID     int
+	ProblemID  int    // 0 for any problem
+	Verdict    string // matched case-insensitively, empty for any verdict
+	LanguageID int    // 0 for any language
+	ContestID  int    // 0 for any contest or none
+	Page       int
+	PageSize   int
+}
+
+type SubmissionListPage struct {
+	Submissions []SubmissionDB `json:"submissions"`
+	Total       int            `json:"total"`
+	Page        int            `json:"page"`
+	PageSize    int            `json:"page_size"`
+	TotalPages  int            `json:"total_pages"`
+}
+
+type SubmissionCode struct {
+	SubmissionID int       `json:"submission_id"`
+	ProblemID    int       `json:"problem_id"`
+	LanguageID   int       `json:"language_id"`
+	Code         string    `json:"code"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type UserDB struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"-"` // bcrypt hash
+	IsAdmin  bool   `json:"is_admin"`
+	IsActive bool   `json:"is_active"`
+}
+
+type UserInfo struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+type SignupPayload struct {
+	Username        string `json:"username"`
+	Email           string `json:"email"`
+	Password        string `json:"password"`
+	ConfirmPassword string `json:"confirm_password"`
+}
+
+type LoginPayload struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type AuthResponse struct {
+	UserInfo
+	Token string `json:"token"` // JWT, also set as the auth_token cookie
+}
+
+type ContestPhase string
+
+const (
+	ContestPhaseUpcoming ContestPhase = "Upcoming"
+	ContestPhaseRunning  ContestPhase = "Running"
+	ContestPhaseEnded    ContestPhase = "Ended"
+)
+
+// ContestProblem places a problem in a contest under a letter label such as "A".
+type ContestProblem struct {
+	Label     string `json:"label"`
+	ProblemID int    `json:"problem_id"`
+	Title     string `json:"title,omitempty"`
+}
+
+type ContestDB struct {
+	ID          int              `json:"id"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	StartTime   time.Time        `json:"start_time"`
+	EndTime     time.Time        `json:"end_time"`
+	Problems    []ContestProblem `json:"problems"`
+	CreatedBy   int              `json:"created_by"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// Phase returns where the contest is at the given time.
+func (c ContestDB) Phase(now time.Time) ContestPhase {
+	switch {
+	case now.Before(c.StartTime):
+		return ContestPhaseUpcoming
+	case now.Before(c.EndTime):
+		return ContestPhaseRunning
+	default:
+		return ContestPhaseEnded
+	}
+}
+
+// ContestInfo is a contest as seen by a user. Problems are omitted before the start.
+type ContestInfo struct {
+	ContestDB
+	Phase        ContestPhase `json:"phase"`
+	Participants int          `json:"participants"`
+	IsRegistered bool         `json:"is_registered"`
+}
+
+type ContestPayload struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	StartTime   time.Time        `json:"start_time"`
+	EndTime     time.Time        `json:"end_time"`
+	Problems    []ContestProblem `json:"problems"` // labels default to A, B, C... in order
+}
+
+// Webhook events delivered to registered URLs.
+const (
+	WebhookEventSubmissionJudged        = "submission.judged"
+	WebhookEventProblemActivated        = "problem.activated"
+	WebhookEventProblemValidationFailed = "problem.validation_failed"
+)
+
+var WebhookEvents = []string{
+	WebhookEventSubmissionJudged,
+	WebhookEventProblemActivated,
+	WebhookEventProblemValidationFailed,
+}
+
+// WebhookDB is a URL that receives the selected events. The secret signs every payload
+// and is only shown when the webhook is created.
+type WebhookDB struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscribes reports whether the webhook wants the event.
+func (w WebhookDB) Subscribes(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookPayload struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret,omitempty"` // generated when empty
+	Active *bool    `json:"active,omitempty"` // defaults to true
+}
+
+// WebhookEvent is the signed JSON body posted to a webhook.
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookAttempt is one POST of a delivery.
+type WebhookAttempt struct {
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int       `json:"duration_ms"`
+	At         time.Time `json:"at"`
+}
+
+// WebhookDelivery is an event sent to one webhook, with every attempt made so far.
+type WebhookDelivery struct {
+	ID          int                   `json:"id"`
+	WebhookID   int                   `json:"webhook_id"`
+	EventID     string                `json:"event_id"`
+	Event       string                `json:"event"`
+	Payload     json.RawMessage       `json:"payload"`
+	Status      WebhookDeliveryStatus `json:"status"`
+	Attempts    []WebhookAttempt      `json:"attempts"`
+	NextRetryAt *time.Time            `json:"next_retry_at,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+}
+
+// Scopes a personal access token can be granted.
+const (
+	TokenScopeRead          = "read"
+	TokenScopeSubmit        = "submit"
+	TokenScopeAdminProblems = "admin:problems"
+)
+
+var TokenScopes = []string{TokenScopeRead, TokenScopeSubmit, TokenScopeAdminProblems}
+
+// PersonalAccessTokenDB is a named API token. Only the SHA-256 hash of the token is
+// stored; Prefix is kept so users can tell their tokens apart.
+type PersonalAccessTokenDB struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope reports whether the token was granted the scope.
+func (t PersonalAccessTokenDB) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type PersonalAccessTokenPayload struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // defaults to 30
+}
+
+// CreatedPersonalAccessToken carries the plain token, which is shown only once.
+type CreatedPersonalAccessToken struct {
+	PersonalAccessTokenDB
+	Token string `json:"token"`
+}
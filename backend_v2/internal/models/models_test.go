@@ -0,0 +1,72 @@
+package models
+
+import "testing"
+
+func TestProblemStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to ProblemStatus
+		want     bool
+	}{
+		{ProblemStatusDraft, ProblemStatusValidating, true},
+		{ProblemStatusDraft, ProblemStatusActive, false},
+		{ProblemStatusValidating, ProblemStatusAwaitingReview, true},
+		{ProblemStatusValidating, ProblemStatusValidationFailed, true},
+		{ProblemStatusValidating, ProblemStatusDraft, false},
+		{ProblemStatusValidationFailed, ProblemStatusDraft, true},
+		{ProblemStatusValidationFailed, ProblemStatusAwaitingReview, false},
+		{ProblemStatusAwaitingReview, ProblemStatusActive, true},
+		{ProblemStatusAwaitingReview, ProblemStatusRejected, true},
+		{ProblemStatusActive, ProblemStatusInactive, true},
+		{ProblemStatusActive, ProblemStatusRejected, false},
+		{ProblemStatusRejected, ProblemStatusActive, false},
+		{ProblemStatusInactive, ProblemStatusActive, true},
+		{ProblemStatus("Unknown"), ProblemStatusDraft, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%q.CanTransitionTo(%q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestSubmissionStateCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to SubmissionState
+		want     bool
+	}{
+		{SubmissionStateQueued, SubmissionStateCompiling, true},
+		{SubmissionStateQueued, SubmissionStateRunning, true},
+		{SubmissionStateQueued, SubmissionStateJudged, true},
+		{SubmissionStateCompiling, SubmissionStateRunning, true},
+		{SubmissionStateCompiling, SubmissionStateQueued, false},
+		{SubmissionStateRunning, SubmissionStateCompiling, false},
+		{SubmissionStateRunning, SubmissionStateFailed, true},
+		{SubmissionStateJudged, SubmissionStateRunning, false},
+		{SubmissionStateJudged, SubmissionStateQueued, true},
+		{SubmissionStateFailed, SubmissionStateQueued, true},
+		{SubmissionStateFailed, SubmissionStateJudged, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%q.CanTransitionTo(%q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestSubmissionStateIsFinal(t *testing.T) {
+	tests := []struct {
+		state SubmissionState
+		want  bool
+	}{
+		{SubmissionStateQueued, false},
+		{SubmissionStateCompiling, false},
+		{SubmissionStateRunning, false},
+		{SubmissionStateJudged, true},
+		{SubmissionStateFailed, true},
+	}
+	for _, tt := range tests {
+		if got := tt.state.IsFinal(); got != tt.want {
+			t.Errorf("%q.IsFinal() = %v, want %v", tt.state, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,491 @@
+// Package problempkg reads and writes problem packages: zip archives laid out as
+//
+//	problem.yaml        title, slug, difficulty, tags, limits, examples, samples...
+//	statement.md        the problem statement in Markdown
+//	editorial.md        optional explanation of the solution
+//	tests/NN.in         test input
+//	tests/NN.out        expected output
+//	checker.py          optional testlib-style checker: checker input output answer
+//	validator.py        optional input validator, exits 0 for a valid input on stdin
+//	generators/NAME.py  test generators, run by the "script" lines of problem.yaml
+//	solutions/NAME.EXT  solutions, tagged in problem.yaml; exactly one is "main"
+//
+// The files may also sit in a single top-level directory, as produced by zipping a
+// checked-out package.
+package problempkg
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	mockdata "online-judge/internal/mock_data"
+	"online-judge/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ManifestFile  = "problem.yaml"
+	statementFile = "statement.md"
+	editorialFile = "editorial.md"
+	checkerFile   = "checker.py"
+	validatorFile = "validator.py"
+	testsDir      = "tests"
+	generatorsDir = "generators"
+	solutionsDir  = "solutions"
+)
+
+// Caps on the decompressed size of archives, so that a small zip cannot expand to exhaust
+// memory.
+const (
+	maxFileBytes    = 64 << 20  // any single file
+	maxArchiveBytes = 256 << 20 // all files read from one archive
+)
+
+type manifest struct {
+	Title       string     `yaml:"title"`
+	Slug        string     `yaml:"slug"`
+	Difficulty  string     `yaml:"difficulty"`
+	Tags        []string   `yaml:"tags,omitempty"`
+	Limits      limits     `yaml:"limits"`
+	Constraints []string   `yaml:"constraints,omitempty"`
+	Examples    []example  `yaml:"examples,omitempty"`
+	Samples     []int      `yaml:"samples,omitempty"` // test numbers shown to users
+	Checker     string     `yaml:"checker,omitempty"`
+	Validator   string     `yaml:"validator,omitempty"`
+	Generators  []string   `yaml:"generators,omitempty"`
+	Script      []string   `yaml:"script,omitempty"` // "generator arg..." per generated test
+	Solutions   []solution `yaml:"solutions"`
+}
+
+type limits struct {
+	TimeMS   int `yaml:"time_ms"`
+	MemoryKB int `yaml:"memory_kb"`
+}
+
+type example struct {
+	Input       string `yaml:"input"`
+	Output      string `yaml:"output"`
+	Explanation string `yaml:"explanation,omitempty"`
+}
+
+type solution struct {
+	File string `yaml:"file"`
+	Tag  string `yaml:"tag"`
+}
+
+// Package is a problem with its tests, as read from or written to an archive.
+type Package struct {
+	Problem   models.ProblemDB
+	TestCases []models.ProblemTestCase
+}
+
+// Read parses a problem package. The problem has no ID or status.
+func Read(archive *zip.Reader) (*Package, error) {
+	files := make(map[string]*zip.File)
+	manifestPath := ""
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := path.Clean(f.Name)
+		files[name] = f
+		if path.Base(name) == ManifestFile && (manifestPath == "" || len(name) < len(manifestPath)) {
+			manifestPath = name
+		}
+	}
+	if manifestPath == "" {
+		return nil, errors.New("package has no " + ManifestFile)
+	}
+	root := strings.TrimSuffix(manifestPath, ManifestFile)
+	budget := newSizeBudget()
+	read := func(name string) (string, error) {
+		f, ok := files[root+name]
+		if !ok {
+			return "", fmt.Errorf("%s is missing", name)
+		}
+		return budget.readFile(f)
+	}
+
+	raw, err := read(ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := yaml.Unmarshal([]byte(raw), &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestFile, err)
+	}
+	if strings.TrimSpace(m.Title) == "" {
+		return nil, fmt.Errorf("%s: title is required", ManifestFile)
+	}
+	if m.Limits.TimeMS <= 0 || m.Limits.MemoryKB <= 0 {
+		return nil, fmt.Errorf("%s: limits.time_ms and limits.memory_kb must be positive", ManifestFile)
+	}
+
+	problem := models.ProblemDB{
+		Title:          m.Title,
+		Slug:           m.Slug,
+		Constraints:    m.Constraints,
+		RuntimeLimitMS: m.Limits.TimeMS,
+		MemoryLimitKB:  m.Limits.MemoryKB,
+		Tags:           []models.Tag{},
+	}
+	if problem.Difficulty, err = difficulty(m.Difficulty); err != nil {
+		return nil, err
+	}
+	for _, name := range m.Tags {
+		problem.Tags = append(problem.Tags, tag(name))
+	}
+	if problem.Description, err = read(statementFile); err != nil {
+		return nil, err
+	}
+	if f, ok := files[root+editorialFile]; ok {
+		if problem.Explaination, err = budget.readFile(f); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.Checker != "" {
+		if path.Ext(m.Checker) != ".py" {
+			return nil, errors.New("the checker must be a Python file")
+		}
+		if problem.CheckerCode, err = read(m.Checker); err != nil {
+			return nil, err
+		}
+		problem.CheckerLanguageID = 2
+	}
+
+	if m.Validator != "" {
+		if path.Ext(m.Validator) != ".py" {
+			return nil, errors.New("the validator must be a Python file")
+		}
+		if problem.ValidatorCode, err = read(m.Validator); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range m.Generators {
+		if path.Ext(file) != ".py" {
+			return nil, fmt.Errorf("%s: generators must be Python files", file)
+		}
+		code, err := read(file)
+		if err != nil {
+			return nil, err
+		}
+		problem.Generators = append(problem.Generators, models.ProblemGenerator{
+			Name: strings.TrimSuffix(path.Base(file), ".py"),
+			Code: code,
+		})
+	}
+	problem.TestScript = m.Script
+
+	if err := readSolutions(&problem, m.Solutions, read); err != nil {
+		return nil, err
+	}
+
+	testFiles := make([]*zip.File, 0)
+	for name, f := range files {
+		if strings.HasPrefix(name, root+testsDir+"/") {
+			testFiles = append(testFiles, f)
+		}
+	}
+	testCases := []models.ProblemTestCase{}
+	if len(testFiles) > 0 || len(problem.TestScript) == 0 {
+		if testCases, err = readTests(testFiles, budget); err != nil {
+			return nil, fmt.Errorf("%s: %w", testsDir, err)
+		}
+	}
+	samples := make(map[int]bool, len(m.Samples))
+	for _, n := range m.Samples {
+		if n < 1 || n > len(testCases) {
+			return nil, fmt.Errorf("%s: sample %d is not a test", ManifestFile, n)
+		}
+		samples[n] = true
+	}
+	for i := range testCases {
+		testCases[i].IsSample = samples[i+1]
+	}
+
+	for i, ex := range m.Examples {
+		problem.Examples = append(problem.Examples, models.ProblemExamples{
+			ID:             i + 1,
+			Input:          ex.Input,
+			ExpectedOutput: ex.Output,
+			Explanation:    ex.Explanation,
+		})
+	}
+	if len(problem.Examples) == 0 {
+		for _, tc := range testCases {
+			if tc.IsSample {
+				problem.Examples = append(problem.Examples, models.ProblemExamples{
+					ID:             len(problem.Examples) + 1,
+					Input:          tc.Input,
+					ExpectedOutput: tc.ExpectedOutput,
+				})
+			}
+		}
+	}
+
+	return &Package{Problem: problem, TestCases: testCases}, nil
+}
+
+func readSolutions(problem *models.ProblemDB, solutions []solution, read func(string) (string, error)) error {
+	known := make(map[string]bool, len(models.SolutionTags))
+	for _, t := range models.SolutionTags {
+		known[t] = true
+	}
+
+	for _, s := range solutions {
+		if !known[s.Tag] {
+			return fmt.Errorf("%s: unknown tag %q", s.File, s.Tag)
+		}
+		languageID, ok := mockdata.LanguageByExtension(path.Ext(s.File))
+		if !ok {
+			return fmt.Errorf("%s: unsupported language", s.File)
+		}
+		code, err := read(s.File)
+		if err != nil {
+			return err
+		}
+
+		if s.Tag == models.SolutionTagMain {
+			if problem.SolutionCode != "" {
+				return errors.New("only one solution can be tagged main")
+			}
+			problem.SolutionCode = code
+			problem.SolutionLanguageID = languageID
+			continue
+		}
+		problem.Solutions = append(problem.Solutions, models.ProblemSolution{
+			Name:       strings.TrimSuffix(path.Base(s.File), path.Ext(s.File)),
+			LanguageID: languageID,
+			Code:       code,
+			Tag:        s.Tag,
+		})
+	}
+	if problem.SolutionCode == "" {
+		return errors.New("a solution tagged main is required")
+	}
+	return nil
+}
+
+// Write archives a problem and its tests as a package. Generated tests are left out, as
+// the script recreates them.
+func Write(w io.Writer, problem models.ProblemDB, testCases []models.ProblemTestCase) error {
+	manual := make([]models.ProblemTestCase, 0, len(testCases))
+	for _, tc := range testCases {
+		if tc.Generator == "" {
+			manual = append(manual, tc)
+		}
+	}
+	testCases = manual
+
+	m := manifest{
+		Title:       problem.Title,
+		Slug:        problem.Slug,
+		Difficulty:  problem.Difficulty.Name,
+		Limits:      limits{TimeMS: problem.RuntimeLimitMS, MemoryKB: problem.MemoryLimitKB},
+		Constraints: problem.Constraints,
+	}
+	for _, t := range problem.Tags {
+		m.Tags = append(m.Tags, t.Name)
+	}
+	for _, ex := range problem.Examples {
+		m.Examples = append(m.Examples, example{Input: ex.Input, Output: ex.ExpectedOutput, Explanation: ex.Explanation})
+	}
+	for i, tc := range testCases {
+		if tc.IsSample {
+			m.Samples = append(m.Samples, i+1)
+		}
+	}
+	if problem.CheckerCode != "" {
+		m.Checker = checkerFile
+	}
+
+	files := map[string]string{statementFile: problem.Description}
+	if problem.Explaination != "" {
+		files[editorialFile] = problem.Explaination
+	}
+	if problem.CheckerCode != "" {
+		files[checkerFile] = problem.CheckerCode
+	}
+	if problem.ValidatorCode != "" {
+		m.Validator = validatorFile
+		files[validatorFile] = problem.ValidatorCode
+	}
+	for _, g := range problem.Generators {
+		name := path.Join(generatorsDir, g.Name+".py")
+		m.Generators = append(m.Generators, name)
+		files[name] = g.Code
+	}
+	m.Script = problem.TestScript
+
+	main := path.Join(solutionsDir, "main"+mockdata.LanguageExtensions[problem.SolutionLanguageID])
+	m.Solutions = append(m.Solutions, solution{File: main, Tag: models.SolutionTagMain})
+	files[main] = problem.SolutionCode
+	for i, s := range problem.Solutions {
+		name := path.Join(solutionsDir, s.Name+mockdata.LanguageExtensions[s.LanguageID])
+		if _, taken := files[name]; taken || s.Name == "" {
+			name = path.Join(solutionsDir, fmt.Sprintf("solution-%d%s", i+1, mockdata.LanguageExtensions[s.LanguageID]))
+		}
+		m.Solutions = append(m.Solutions, solution{File: name, Tag: s.Tag})
+		files[name] = s.Code
+	}
+
+	for i, tc := range testCases {
+		base := path.Join(testsDir, fmt.Sprintf("%02d", i+1))
+		files[base+".in"] = tc.Input
+		files[base+".out"] = tc.ExpectedOutput
+	}
+
+	manifestData, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	files[ManifestFile] = string(manifestData)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	zw := zip.NewWriter(w)
+	for _, name := range names {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, files[name]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// ReadTests pairs NN.in and NN.out files, ignoring directories and any other files, and
+// returns the tests ordered by NN. Two files for the same test, such as a/01.in and
+// b/1.in, are an error.
+func ReadTests(files []*zip.File) ([]models.ProblemTestCase, error) {
+	return readTests(files, newSizeBudget())
+}
+
+func readTests(files []*zip.File, budget *sizeBudget) ([]models.ProblemTestCase, error) {
+	type pair struct {
+		input, output *string
+	}
+	pairs := make(map[int]*pair)
+	seen := make(map[string]string)
+
+	for _, f := range files {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := path.Base(f.Name)
+		ext := path.Ext(name)
+		if ext != ".in" && ext != ".out" {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(name, ext))
+		if err != nil {
+			return nil, fmt.Errorf("%s: test files must be named NN.in / NN.out", f.Name)
+		}
+		key := fmt.Sprintf("%d%s", n, ext)
+		if other, ok := seen[key]; ok {
+			return nil, fmt.Errorf("%s and %s are the same test", other, f.Name)
+		}
+		seen[key] = f.Name
+
+		content, err := budget.readFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+
+		if pairs[n] == nil {
+			pairs[n] = &pair{}
+		}
+		if ext == ".in" {
+			pairs[n].input = &content
+		} else {
+			pairs[n].output = &content
+		}
+	}
+
+	if len(pairs) == 0 {
+		return nil, errors.New("archive contains no NN.in / NN.out files")
+	}
+
+	numbers := make([]int, 0, len(pairs))
+	for n, p := range pairs {
+		if p.input == nil || p.output == nil {
+			return nil, fmt.Errorf("test %02d is missing its .in or .out file", n)
+		}
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	testCases := make([]models.ProblemTestCase, 0, len(numbers))
+	for _, n := range numbers {
+		testCases = append(testCases, models.ProblemTestCase{
+			Input:          *pairs[n].input,
+			ExpectedOutput: *pairs[n].output,
+		})
+	}
+	return testCases, nil
+}
+
+// sizeBudget counts down the bytes that may still be decompressed from an archive.
+type sizeBudget struct {
+	left int64
+}
+
+func newSizeBudget() *sizeBudget {
+	return &sizeBudget{left: maxArchiveBytes}
+}
+
+// readFile reads a file within maxFileBytes and what is left of the budget.
+func (b *sizeBudget) readFile(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	limit := min(maxFileBytes, b.left)
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > limit {
+		if limit < maxFileBytes {
+			return "", fmt.Errorf("%s: archive expands to more than %d MiB", f.Name, maxArchiveBytes>>20)
+		}
+		return "", fmt.Errorf("%s is larger than %d MiB", f.Name, maxFileBytes>>20)
+	}
+	b.left -= int64(len(data))
+	return string(data), nil
+}
+
+func difficulty(name string) (models.Difficulty, error) {
+	for _, d := range mockdata.Difficulties {
+		if strings.EqualFold(d.Name, name) {
+			return d, nil
+		}
+	}
+	return models.Difficulty{}, fmt.Errorf("%s: unknown difficulty %q", ManifestFile, name)
+}
+
+// tag returns the known tag with the name, or a new tag without an ID.
+func tag(name string) models.Tag {
+	for _, t := range mockdata.Tags {
+		if strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return models.Tag{Name: name}
+}
@@ -0,0 +1,184 @@
+package problempkg
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// archive zips the files, in the given order, and opens the result.
+func archive(t *testing.T, files ...string) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		fw, err := zw.Create(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(fw, files[i+1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zr
+}
+
+func TestReadTests(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		inputs  []string
+		wantErr string
+	}{
+		{
+			name:   "ordered by number",
+			files:  []string{"10.in", "c", "10.out", "C", "tests/2.in", "b", "tests/2.out", "B", "readme.md", "x"},
+			inputs: []string{"b", "c"},
+		},
+		{name: "empty", files: []string{"readme.md", "x"}, wantErr: "no NN.in"},
+		{name: "bad name", files: []string{"one.in", "1"}, wantErr: "must be named"},
+		{name: "missing output", files: []string{"01.in", "1"}, wantErr: "missing its .in or .out"},
+		{
+			name:    "duplicate",
+			files:   []string{"a/01.in", "1", "a/01.out", "1", "b/1.in", "2"},
+			wantErr: "are the same test",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadTests(archive(t, tt.files...).File)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.inputs) {
+				t.Fatalf("got %d tests, want %d", len(got), len(tt.inputs))
+			}
+			for i, tc := range got {
+				if tc.Input != tt.inputs[i] {
+					t.Errorf("test %d input = %q, want %q", i+1, tc.Input, tt.inputs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadTestsFileTooLarge(t *testing.T) {
+	zr := archive(t, "01.in", strings.Repeat("0", maxFileBytes+1), "01.out", "0")
+	if _, err := ReadTests(zr.File); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Fatalf("err = %v, want a size error", err)
+	}
+}
+
+const testManifest = `title: Echo
+slug: echo
+difficulty: easy
+limits: {time_ms: 1000, memory_kb: 65536}
+samples: [1]
+checker: checker.py
+solutions:
+  - {file: solutions/main.py, tag: main}
+  - {file: solutions/slow.py, tag: time-limit-exceeded}
+`
+
+func TestRead(t *testing.T) {
+	valid := []string{
+		"echo/problem.yaml", testManifest,
+		"echo/statement.md", "Print the input.",
+		"echo/checker.py", "import sys",
+		"echo/solutions/main.py", "print(input())",
+		"echo/solutions/slow.py", "while True: pass",
+		"echo/tests/01.in", "1",
+		"echo/tests/01.out", "1",
+		"echo/tests/02.in", "2",
+		"echo/tests/02.out", "2",
+	}
+	replace := func(name, content string) []string {
+		files := append([]string(nil), valid...)
+		for i := 0; i < len(files); i += 2 {
+			if files[i] == name {
+				files[i+1] = content
+			}
+		}
+		return files
+	}
+
+	tests := []struct {
+		name    string
+		files   []string
+		wantErr string
+	}{
+		{name: "valid", files: valid},
+		{name: "no manifest", files: []string{"statement.md", "x"}, wantErr: "has no problem.yaml"},
+		{name: "no title", files: replace("echo/problem.yaml", "limits: {time_ms: 1, memory_kb: 1}"), wantErr: "title is required"},
+		{
+			name:    "no limits",
+			files:   replace("echo/problem.yaml", strings.Replace(testManifest, "time_ms: 1000", "time_ms: 0", 1)),
+			wantErr: "must be positive",
+		},
+		{name: "missing statement", files: append(valid[:2:2], valid[4:]...), wantErr: "statement.md is missing"},
+		{
+			name:    "unknown language",
+			files:   replace("echo/problem.yaml", strings.Replace(testManifest, "slow.py", "slow.rb", 1)),
+			wantErr: "unsupported language",
+		},
+		{
+			name:    "sample out of range",
+			files:   replace("echo/problem.yaml", strings.Replace(testManifest, "samples: [1]", "samples: [3]", 1)),
+			wantErr: "sample 3 is not a test",
+		},
+		{name: "duplicate test", files: append(valid[:len(valid):len(valid)], "echo/tests/1.out", "1"), wantErr: "are the same test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg, err := Read(archive(t, tt.files...))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := pkg.Problem
+			if p.Title != "Echo" || p.RuntimeLimitMS != 1000 || p.CheckerCode != "import sys" || p.SolutionLanguageID != 2 {
+				t.Errorf("problem = %+v", p)
+			}
+			if len(p.Solutions) != 1 || p.Solutions[0].Name != "slow" || p.Solutions[0].LanguageID != 2 {
+				t.Errorf("solutions = %+v", p.Solutions)
+			}
+			if len(pkg.TestCases) != 2 || !pkg.TestCases[0].IsSample || pkg.TestCases[1].IsSample {
+				t.Errorf("tests = %+v", pkg.TestCases)
+			}
+			if len(p.Examples) != 1 || p.Examples[0].Input != "1" {
+				t.Errorf("examples = %+v", p.Examples)
+			}
+		})
+	}
+}
+
+func TestSizeBudget(t *testing.T) {
+	zr := archive(t, "a.txt", "0123456789", "b.txt", "0123456789")
+	budget := &sizeBudget{left: 15}
+	if _, err := budget.readFile(zr.File[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := budget.readFile(zr.File[1]); err == nil || !strings.Contains(err.Error(), "archive expands") {
+		t.Fatalf("err = %v, want the archive budget to run out", err)
+	}
+}
@@ -0,0 +1,137 @@
+// Package realtime pushes submission verdicts to the WebSocket clients connected to this
+// API replica. Verdicts reach every replica through Redis pub/sub, see
+// services.RedisService.StartVerdictSubscriber.
+package realtime
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+
+	"online-judge/internal/models"
+)
+
+// sendBuffer is how many messages may wait for a slow client before it is dropped.
+const sendBuffer = 32
+
+// Client is one WebSocket connection of a user, subscribed to some of their submissions.
+type Client struct {
+	UserID int
+	Send   chan []byte // closed by the hub when the client is unregistered
+
+	mu          sync.Mutex
+	submissions map[int]bool
+}
+
+// Subscribe starts delivering the verdicts of the given submissions to the client.
+func (c *Client) Subscribe(ids ...int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		c.submissions[id] = true
+	}
+}
+
+// Unsubscribe stops delivering the verdicts of the given submissions.
+func (c *Client) Unsubscribe(ids ...int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		delete(c.submissions, id)
+	}
+}
+
+func (c *Client) subscribed(id int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.submissions[id]
+}
+
+// Hub tracks the connected clients by user.
+type Hub struct {
+	mu      sync.Mutex
+	clients map[int]map[*Client]bool // user ID -> clients
+}
+
+func NewHub() *Hub {
+	return &Hub{clients: make(map[int]map[*Client]bool)}
+}
+
+// Register adds a connection of a user.
+func (h *Hub) Register(userID int) *Client {
+	c := &Client{
+		UserID:      userID,
+		Send:        make(chan []byte, sendBuffer),
+		submissions: make(map[int]bool),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[*Client]bool)
+	}
+	h.clients[userID][c] = true
+	return c
+}
+
+// Unregister removes a connection and closes its Send channel. It is safe to call twice.
+func (h *Hub) Unregister(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.unregister(c)
+}
+
+// unregister does the work of Unregister. Callers must hold h.mu.
+func (h *Hub) unregister(c *Client) {
+	if !h.clients[c.UserID][c] {
+		return
+	}
+	delete(h.clients[c.UserID], c)
+	if len(h.clients[c.UserID]) == 0 {
+		delete(h.clients, c.UserID)
+	}
+	close(c.Send)
+}
+
+// Deliver sends a verdict to the owner's clients subscribed to the submission. Clients
+// that fall behind are dropped rather than blocking delivery to everybody else.
+func (h *Hub) Deliver(event *models.VerdictEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Println("Failed to encode verdict: ", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients[event.UserID] {
+		if !c.subscribed(event.SubmissionID) {
+			continue
+		}
+		select {
+		case c.Send <- data:
+		default:
+			h.unregister(c)
+		}
+	}
+}
+
+// SendTo sends a verdict to a single client, e.g. when it subscribes to a submission that
+// was already judged.
+func (h *Hub) SendTo(c *Client, event *models.VerdictEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.clients[c.UserID][c] {
+		return
+	}
+	select {
+	case c.Send <- data:
+	default:
+		h.unregister(c)
+	}
+}
@@ -0,0 +1,186 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"online-judge/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+type ContestRepo struct {
+	mu            sync.RWMutex
+	db            []models.ContestDB
+	registrations map[int]map[int]time.Time // contest ID -> user ID -> registration time
+}
+
+func NewContestRepo() *ContestRepo {
+	return &ContestRepo{
+		db:            make([]models.ContestDB, 0),
+		registrations: make(map[int]map[int]time.Time),
+	}
+}
+
+// CreateContest stores a new contest and returns its ID
+func (r *ContestRepo) CreateContest(ctx context.Context, contest models.ContestDB) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	contest.ID = len(r.db) + 1
+	contest.CreatedAt = time.Now()
+	r.db = append(r.db, contest)
+	return contest.ID, nil
+}
+
+// UpdateContest replaces the title, description, window and problem set of a contest
+func (r *ContestRepo) UpdateContest(ctx context.Context, updated models.ContestDB) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, err := r.find(updated.ID)
+	if err != nil {
+		return err
+	}
+	c.Title = updated.Title
+	c.Description = updated.Description
+	c.StartTime = updated.StartTime
+	c.EndTime = updated.EndTime
+	c.Problems = updated.Problems
+	return nil
+}
+
+func (r *ContestRepo) GetContest(ctx context.Context, id int) (*models.ContestDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, err := r.find(id)
+	if err != nil {
+		return nil, err
+	}
+	contest := *c
+	contest.Problems = append([]models.ContestProblem{}, c.Problems...)
+	return &contest, nil
+}
+
+// ListContests returns every contest, the latest start first
+func (r *ContestRepo) ListContests(ctx context.Context) ([]models.ContestDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	contests := make([]models.ContestDB, len(r.db))
+	for i, c := range r.db {
+		contests[i] = c
+		contests[i].Problems = append([]models.ContestProblem{}, c.Problems...)
+	}
+	sort.SliceStable(contests, func(i, j int) bool {
+		return contests[i].StartTime.After(contests[j].StartTime)
+	})
+	return contests, nil
+}
+
+// Register signs a user up for a contest that has not ended. Registering twice is a no-op.
+func (r *ContestRepo) Register(ctx context.Context, contestID, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, err := r.find(contestID)
+	if err != nil {
+		return err
+	}
+	if c.Phase(time.Now()) == models.ContestPhaseEnded {
+		return errors.New("contest has ended")
+	}
+
+	if r.registrations[contestID] == nil {
+		r.registrations[contestID] = make(map[int]time.Time)
+	}
+	if _, ok := r.registrations[contestID][userID]; !ok {
+		r.registrations[contestID][userID] = time.Now()
+	}
+	return nil
+}
+
+func (r *ContestRepo) IsRegistered(ctx context.Context, contestID, userID int) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.registrations[contestID][userID]
+	return ok
+}
+
+func (r *ContestRepo) CountParticipants(ctx context.Context, contestID int) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.registrations[contestID])
+}
+
+// HiddenProblemIDs returns the problems of every contest that has not ended by now.
+// They stay out of the public problem list until their contest is over.
+func (r *ContestRepo) HiddenProblemIDs(ctx context.Context, now time.Time) map[int]bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hidden := make(map[int]bool)
+	for _, c := range r.db {
+		if c.Phase(now) != models.ContestPhaseEnded {
+			for _, p := range c.Problems {
+				hidden[p.ProblemID] = true
+			}
+		}
+	}
+	return hidden
+}
+
+// CanAccessProblem reports whether a user may see and run a problem: either it belongs to
+// no unfinished contest, or the user is registered for a running contest containing it.
+func (r *ContestRepo) CanAccessProblem(ctx context.Context, problemID, userID int, now time.Time) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hidden := false
+	for _, c := range r.db {
+		phase := c.Phase(now)
+		if phase == models.ContestPhaseEnded || !containsProblem(c, problemID) {
+			continue
+		}
+		if _, registered := r.registrations[c.ID][userID]; registered && phase == models.ContestPhaseRunning {
+			return true
+		}
+		hidden = true
+	}
+	return !hidden
+}
+
+// InStartedContest reports whether a problem belongs to a contest that has started.
+func (r *ContestRepo) InStartedContest(ctx context.Context, problemID int, now time.Time) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.db {
+		if c.Phase(now) != models.ContestPhaseUpcoming && containsProblem(c, problemID) {
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the contest with the given ID. Callers must hold r.mu.
+func (r *ContestRepo) find(id int) (*models.ContestDB, error) {
+	for i := range r.db {
+		if r.db[i].ID == id {
+			return &r.db[i], nil
+		}
+	}
+	return nil, errors.New("contest not found")
+}
+
+func containsProblem(c models.ContestDB, problemID int) bool {
+	for _, p := range c.Problems {
+		if p.ProblemID == problemID {
+			return true
+		}
+	}
+	return false
+}
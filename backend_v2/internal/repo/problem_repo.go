@@ -3,12 +3,24 @@ package repo
 import (
 	"context"
 	"errors"
+	"fmt"
 	"online-judge/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 type ProblemRepo struct {
-	db        []models.ProblemDB
-	testCases []models.ProblemTestCase
+	mu                sync.RWMutex
+	db                []models.ProblemDB
+	testCases         map[int][]models.ProblemTestCase // problem ID -> test cases ordered by Position
+	nextTestCaseID    int
+	transitions       []models.ProblemStatusTransition
+	validationReports map[int]models.ProblemValidationReport
+	searchIndex       *searchIndex
+	solvedBy          map[int]map[int]bool // user ID -> set of solved problem IDs
+	userStats         map[int]*models.UserStats
 }
 
 func NewProblemRepo() *ProblemRepo {
@@ -21,13 +33,12 @@ func NewProblemRepo() *ProblemRepo {
 			Slug:               "add-two-numbers",
 			Tags:               []models.Tag{{ID: 1, Name: "Math"}},
 			Difficulty:         models.Difficulty{ID: 1, Name: "Easy"},
-			AcceptanceRate:     95.5,
-			SolutionLanguageID: 1, // Python
+			SolutionLanguageID: 2, // Python
 			SolutionCode: `a = int(input())
 b = int(input())
 print(a + b)`,
 			Explaination:   "Read two integers from stdin and print their sum.",
-			Status:         "Active",
+			Status:         models.ProblemStatusActive,
 			RuntimeLimitMS: 1000,
 			MemoryLimitKB:  65536,
 			Examples: []models.ProblemExamples{
@@ -42,105 +53,476 @@ print(a + b)`,
 	}
 
 	// Example test cases
-	testCases := []models.ProblemTestCase{
-		{ID: 1, Input: "2\n3", ExpectedOutput: "5"},
-		{ID: 2, Input: "-1\n1", ExpectedOutput: "0"},
-		{ID: 3, Input: "1000000\n2345678", ExpectedOutput: "3345678"},
+	testCases := map[int][]models.ProblemTestCase{
+		1: {
+			{ID: 1, ProblemID: 1, Input: "2\n3", ExpectedOutput: "5", IsSample: true, Position: 1},
+			{ID: 2, ProblemID: 1, Input: "-1\n1", ExpectedOutput: "0", Position: 2},
+			{ID: 3, ProblemID: 1, Input: "1000000\n2345678", ExpectedOutput: "3345678", Position: 3},
+		},
 	}
 
 	// Initialize the ProblemRepo
 	problemRepo := ProblemRepo{
-		db:        problems,
-		testCases: testCases,
+		db:                problems,
+		testCases:         testCases,
+		nextTestCaseID:    4,
+		validationReports: make(map[int]models.ProblemValidationReport),
+		searchIndex:       newSearchIndex(),
+		solvedBy:          make(map[int]map[int]bool),
+		userStats:         make(map[int]*models.UserStats),
+	}
+	for _, p := range problems {
+		problemRepo.searchIndex.Index(p.ID, p.Title, p.Description)
 	}
 
 	return &problemRepo
 }
 
-// GetProblems returns all problems with status "Active" as []ProblemInfo.
-func (r *ProblemRepo) GetProblems(ctx context.Context, isAdmin bool) ([]models.ProblemInfo, error) {
-	var result []models.ProblemInfo
+// GetProblems returns the problems matching the query as a page of []ProblemInfo.
+// Non-admins only see problems with status "Active" that are not hidden by the query.
+func (r *ProblemRepo) GetProblems(ctx context.Context, isAdmin bool, query models.ProblemListQuery) (*models.ProblemListPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []models.ProblemDB
+	for _, p := range r.db {
+		if (isAdmin || (p.Status == models.ProblemStatusActive && !query.HiddenIDs[p.ID])) && r.matchesProblemQuery(p, query) {
+			matched = append(matched, p)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if query.Descending {
+			a, b = b, a
+		}
+		switch query.SortBy {
+		case "acceptance":
+			if a.AcceptanceRate != b.AcceptanceRate {
+				return a.AcceptanceRate < b.AcceptanceRate
+			}
+		case "difficulty":
+			if a.Difficulty.ID != b.Difficulty.ID {
+				return a.Difficulty.ID < b.Difficulty.ID
+			}
+		}
+		return a.ID < b.ID
+	})
+
+	page := &models.ProblemListPage{
+		Problems:   []models.ProblemInfo{},
+		Total:      len(matched),
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: (len(matched) + query.PageSize - 1) / query.PageSize,
+	}
+
+	start := (query.Page - 1) * query.PageSize
+	end := min(start+query.PageSize, len(matched))
+	for i := start; i < end; i++ {
+		page.Problems = append(page.Problems, r.toProblemInfo(matched[i], query.UserID))
+	}
+	return page, nil
+}
+
+// SearchProblems runs a full-text search over problem titles and descriptions.
+// Non-admins only see problems with status "Active" that are not hidden.
+func (r *ProblemRepo) SearchProblems(ctx context.Context, userID int, isAdmin bool, hidden map[int]bool, text string, page, pageSize int) (*models.ProblemSearchPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byID := make(map[int]models.ProblemDB, len(r.db))
 	for _, p := range r.db {
-		if p.Status == "Active" || isAdmin {
-			result = append(result, models.ProblemInfo{
-				ID:             p.ID,
-				Title:          p.Title,
-				Slug:           p.Slug,
-				Tags:           p.Tags,
-				Difficulty:     p.Difficulty,
-				AcceptanceRate: p.AcceptanceRate,
-				IsSolved:       p.IsSolved,
-			})
+		if isAdmin || (p.Status == models.ProblemStatusActive && !hidden[p.ID]) {
+			byID[p.ID] = p
 		}
 	}
+
+	var hits []models.ProblemSearchHit
+	for _, match := range r.searchIndex.Search(text) {
+		p, ok := byID[match.problemID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, models.ProblemSearchHit{
+			ProblemInfo:    r.toProblemInfo(p, userID),
+			Rank:           match.rank,
+			TitleHighlight: highlight(p.Title, text, false),
+			Snippet:        highlight(p.Description, text, true),
+		})
+	}
+
+	result := &models.ProblemSearchPage{
+		Query:      text,
+		Hits:       []models.ProblemSearchHit{},
+		Total:      len(hits),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: (len(hits) + pageSize - 1) / pageSize,
+	}
+	start := min((page-1)*pageSize, len(hits))
+	end := min(start+pageSize, len(hits))
+	result.Hits = append(result.Hits, hits[start:end]...)
 	return result, nil
 }
 
-// GetProblemByID returns a single ProblemDetail with status "Active".
-func (r *ProblemRepo) GetProblemByID(ctx context.Context, problemId int) (*models.ProblemDetail, error) {
+// toProblemInfo converts a problem to its list form for the given user. Callers must hold r.mu.
+func (r *ProblemRepo) toProblemInfo(p models.ProblemDB, userID int) models.ProblemInfo {
+	return models.ProblemInfo{
+		ID:             p.ID,
+		Title:          p.Title,
+		Slug:           p.Slug,
+		Tags:           p.Tags,
+		Difficulty:     p.Difficulty,
+		AcceptanceRate: p.AcceptanceRate,
+		IsSolved:       r.solvedBy[userID][p.ID],
+	}
+}
+
+// matchesProblemQuery reports whether a problem passes the filters of a list query. Callers must hold r.mu.
+func (r *ProblemRepo) matchesProblemQuery(p models.ProblemDB, query models.ProblemListQuery) bool {
+	if query.Difficulty != "" && !strings.EqualFold(p.Difficulty.Name, query.Difficulty) {
+		return false
+	}
+	if query.Search != "" && !strings.Contains(strings.ToLower(p.Title), strings.ToLower(query.Search)) {
+		return false
+	}
+	if query.Solved != nil && r.solvedBy[query.UserID][p.ID] != *query.Solved {
+		return false
+	}
+	if len(query.Tags) == 0 {
+		return true
+	}
+
+	found := 0
+	for _, want := range query.Tags {
+		for _, tag := range p.Tags {
+			if strings.EqualFold(tag.Name, want) {
+				found++
+				break
+			}
+		}
+	}
+	if query.MatchAllTag {
+		return found == len(query.Tags)
+	}
+	return found > 0
+}
+
+// GetProblemByID returns a single ProblemDetail with status "Active", or also "Awaiting
+// Review" when awaitingReview is set, as seen by the given user.
+func (r *ProblemRepo) GetProblemByID(ctx context.Context, problemId, userID int, awaitingReview bool) (*models.ProblemDetail, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	for _, p := range r.db {
-		if p.ID == problemId && p.Status == "Active" {
+		if p.ID == problemId && (p.Status == models.ProblemStatusActive ||
+			awaitingReview && p.Status == models.ProblemStatusAwaitingReview) {
 			return &models.ProblemDetail{
-				ID:             p.ID,
-				Title:          p.Title,
-				Description:    p.Description,
-				Constraints:    p.Constraints,
-				Slug:           p.Slug,
-				Tags:           p.Tags,
-				Difficulty:     p.Difficulty,
-				AcceptanceRate: p.AcceptanceRate,
-				IsSolved:       p.IsSolved,
-				Examples:       p.Examples,
+				ID:                  p.ID,
+				Title:               p.Title,
+				Description:         p.Description,
+				Constraints:         p.Constraints,
+				Slug:                p.Slug,
+				Tags:                p.Tags,
+				Difficulty:          p.Difficulty,
+				AcceptanceRate:      p.AcceptanceRate,
+				TotalSubmissions:    p.TotalSubmissions,
+				AcceptedSubmissions: p.AcceptedSubmissions,
+				SolvedCount:         p.SolvedCount,
+				IsSolved:            r.solvedBy[userID][p.ID],
+				Examples:            p.Examples,
 			}, nil
 		}
 	}
 	return nil, errors.New("active problem not found")
 }
 
-// CreateProblem adds a new problem with status "In Review".
+// CreateProblem adds a new problem with status "Draft" and no judging statistics.
 func (r *ProblemRepo) CreateProblem(ctx context.Context, problem *models.ProblemDB) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	problem.ID = len(r.db) + 1
-	problem.Status = "In Review"
+	problem.Status = models.ProblemStatusDraft
+	problem.TotalSubmissions, problem.AcceptedSubmissions, problem.SolvedCount, problem.AcceptanceRate = 0, 0, 0, 0
 	r.db = append(r.db, *problem)
+	r.searchIndex.Index(problem.ID, problem.Title, problem.Description)
 	return problem.ID, nil
 }
 
-// UpdateProblemByID updates a problem by ID, sets status to "In Review".
-func (r *ProblemRepo) UpdateProblemByID(ctx context.Context, updated *models.ProblemDB) error {
+// UpdateProblemByID updates a problem by ID and sends it back to "Draft". The judging
+// statistics are kept, whatever the update carries.
+func (r *ProblemRepo) UpdateProblemByID(ctx context.Context, updated *models.ProblemDB, actorID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	for i := range r.db {
 		if r.db[i].ID == updated.ID {
-			updated.Status = "In Review"
+			from := r.db[i].Status
+			if from != models.ProblemStatusDraft && !from.CanTransitionTo(models.ProblemStatusDraft) {
+				return fmt.Errorf("problem in status %q cannot be edited", from)
+			}
+			updated.Status = models.ProblemStatusDraft
+			updated.TotalSubmissions = r.db[i].TotalSubmissions
+			updated.AcceptedSubmissions = r.db[i].AcceptedSubmissions
+			updated.SolvedCount = r.db[i].SolvedCount
+			updated.AcceptanceRate = r.db[i].AcceptanceRate
 			r.db[i] = *updated
+			r.searchIndex.Index(updated.ID, updated.Title, updated.Description)
+			if from != models.ProblemStatusDraft {
+				r.recordTransition(updated.ID, from, models.ProblemStatusDraft, actorID, "problem edited")
+			}
 			return nil
 		}
 	}
 	return errors.New("problem not found")
 }
 
-// UpdateProblemStatusByID updates only the status of the problem by ID.
-func (r *ProblemRepo) UpdateProblemStatusByID(ctx context.Context, problemID int, status string) error {
+// TransitionProblemStatus moves a problem to the given status if the state machine allows it,
+// and records the transition in the audit trail.
+func (r *ProblemRepo) TransitionProblemStatus(ctx context.Context, problemID int, to models.ProblemStatus, actorID int, comment string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	for i := range r.db {
 		if r.db[i].ID == problemID {
-			r.db[i].Status = status
+			from := r.db[i].Status
+			if !from.CanTransitionTo(to) {
+				return fmt.Errorf("invalid status transition from %q to %q", from, to)
+			}
+			r.db[i].Status = to
+			r.recordTransition(problemID, from, to, actorID, comment)
 			return nil
 		}
 	}
 	return errors.New("problem not found")
 }
 
+// SetRuntimeLimit replaces the base time limit of a problem without sending it back to
+// "Draft", for limits calibrated during validation.
+func (r *ProblemRepo) SetRuntimeLimit(ctx context.Context, problemID, runtimeLimitMS int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.db {
+		if r.db[i].ID == problemID {
+			r.db[i].RuntimeLimitMS = runtimeLimitMS
+			return nil
+		}
+	}
+	return errors.New("problem not found")
+}
+
+// recordTransition appends to the audit trail. Callers must hold r.mu.
+func (r *ProblemRepo) recordTransition(problemID int, from, to models.ProblemStatus, actorID int, comment string) {
+	r.transitions = append(r.transitions, models.ProblemStatusTransition{
+		ID:        len(r.transitions) + 1,
+		ProblemID: problemID,
+		From:      from,
+		To:        to,
+		ActorID:   actorID,
+		Comment:   comment,
+		CreatedAt: time.Now(),
+	})
+}
+
+// GetProblemTransitions returns the audit trail of status transitions for a problem, oldest first.
+func (r *ProblemRepo) GetProblemTransitions(ctx context.Context, problemID int) ([]models.ProblemStatusTransition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := []models.ProblemStatusTransition{}
+	for _, t := range r.transitions {
+		if t.ProblemID == problemID {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+// SaveValidationReport stores the latest validation report of a problem, replacing any earlier one.
+func (r *ProblemRepo) SaveValidationReport(ctx context.Context, report models.ProblemValidationReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.validationReports[report.ProblemID] = report
+	return nil
+}
+
+// GetValidationReport returns the latest validation report of a problem.
+func (r *ProblemRepo) GetValidationReport(ctx context.Context, problemID int) (*models.ProblemValidationReport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	report, ok := r.validationReports[problemID]
+	if !ok {
+		return nil, errors.New("validation report not found")
+	}
+	return &report, nil
+}
+
+// GetProblemTestCases returns the test cases of a problem in execution order.
 func (r *ProblemRepo) GetProblemTestCases(ctx context.Context, problemId int) ([]models.ProblemTestCase, error) {
-	if len(r.testCases) == 0 {
-		return nil, errors.New("test cases not found")
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.problemExists(problemId) {
+		return nil, errors.New("problem not found")
+	}
+
+	return append([]models.ProblemTestCase{}, r.testCases[problemId]...), nil
+}
+
+// AddProblemTestCases appends test cases to a problem, assigning IDs and positions.
+// When replace is true the existing test cases of the problem are dropped first.
+func (r *ProblemRepo) AddProblemTestCases(ctx context.Context, problemID int, testCases []models.ProblemTestCase, replace bool) ([]models.ProblemTestCase, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.problemExists(problemID) {
+		return nil, errors.New("problem not found")
+	}
+
+	existing := r.testCases[problemID]
+	if replace {
+		existing = nil
+	}
+
+	added := make([]models.ProblemTestCase, 0, len(testCases))
+	for _, tc := range testCases {
+		tc.ID = r.nextTestCaseID
+		tc.ProblemID = problemID
+		tc.Position = len(existing) + 1
+		r.nextTestCaseID++
+		existing = append(existing, tc)
+		added = append(added, tc)
+	}
+	r.testCases[problemID] = existing
+	return added, nil
+}
+
+// ReplaceGeneratedTestCases swaps the generated tests of a problem for a new set. Tests
+// written by hand keep their order and come first.
+func (r *ProblemRepo) ReplaceGeneratedTestCases(ctx context.Context, problemID int, generated []models.ProblemTestCase) ([]models.ProblemTestCase, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.problemExists(problemID) {
+		return nil, errors.New("problem not found")
 	}
 
-	return r.testCases, nil
+	testCases := make([]models.ProblemTestCase, 0, len(r.testCases[problemID])+len(generated))
+	for _, tc := range r.testCases[problemID] {
+		if tc.Generator == "" {
+			tc.Position = len(testCases) + 1
+			testCases = append(testCases, tc)
+		}
+	}
+	for _, tc := range generated {
+		tc.ID = r.nextTestCaseID
+		tc.ProblemID = problemID
+		tc.Position = len(testCases) + 1
+		tc.IsSample = false
+		r.nextTestCaseID++
+		testCases = append(testCases, tc)
+	}
+	r.testCases[problemID] = testCases
+
+	result := make([]models.ProblemTestCase, len(testCases))
+	copy(result, testCases)
+	return result, nil
 }
 
-func (r *ProblemRepo) GetProblemMetadata(ctx context.Context, problemId int) (*models.ProblemDB, error) {
+// UpdateProblemTestCase replaces the input, expected output and sample flag of a test case.
+func (r *ProblemRepo) UpdateProblemTestCase(ctx context.Context, problemID int, updated models.ProblemTestCase) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	testCases := r.testCases[problemID]
+	for i := range testCases {
+		if testCases[i].ID == updated.ID {
+			testCases[i].Input = updated.Input
+			testCases[i].ExpectedOutput = updated.ExpectedOutput
+			testCases[i].IsSample = updated.IsSample
+			return nil
+		}
+	}
+	return errors.New("test case not found")
+}
+
+// DeleteProblemTestCase removes a test case and closes the gap in positions.
+func (r *ProblemRepo) DeleteProblemTestCase(ctx context.Context, problemID, testCaseID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	testCases := r.testCases[problemID]
+	for i := range testCases {
+		if testCases[i].ID == testCaseID {
+			testCases = append(testCases[:i], testCases[i+1:]...)
+			for j := range testCases {
+				testCases[j].Position = j + 1
+			}
+			r.testCases[problemID] = testCases
+			return nil
+		}
+	}
+	return errors.New("test case not found")
+}
+
+// ReorderProblemTestCases sets the execution order of a problem's test cases.
+// ids must contain every test case ID of the problem exactly once.
+func (r *ProblemRepo) ReorderProblemTestCases(ctx context.Context, problemID int, ids []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	testCases := r.testCases[problemID]
+	if len(ids) != len(testCases) {
+		return fmt.Errorf("expected %d test case IDs, got %d", len(testCases), len(ids))
+	}
+
+	byID := make(map[int]models.ProblemTestCase, len(testCases))
+	for _, tc := range testCases {
+		byID[tc.ID] = tc
+	}
+
+	reordered := make([]models.ProblemTestCase, 0, len(ids))
+	for i, id := range ids {
+		tc, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("test case %d is missing or listed twice", id)
+		}
+		delete(byID, id)
+		tc.Position = i + 1
+		reordered = append(reordered, tc)
+	}
+	r.testCases[problemID] = reordered
+	return nil
+}
+
+// problemExists reports whether a problem with the given ID exists. Callers must hold r.mu.
+func (r *ProblemRepo) problemExists(problemID int) bool {
 	for _, p := range r.db {
-		if p.ID == problemId && p.Status == "Active" {
+		if p.ID == problemID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetProblemMetadata returns the full problem record. Unless activeOnly is false,
+// only problems with status "Active" are returned.
+func (r *ProblemRepo) GetProblemMetadata(ctx context.Context, problemId int, activeOnly bool) (*models.ProblemDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, p := range r.db {
+		if p.ID == problemId && (p.Status == models.ProblemStatusActive || !activeOnly) {
 			return &p, nil
 		}
 	}
-	return nil, errors.New("active problem not found")
+	if activeOnly {
+		return nil, errors.New("active problem not found")
+	}
+	return nil, errors.New("problem not found")
 }
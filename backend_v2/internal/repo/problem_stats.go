@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"online-judge/internal/models"
+)
+
+// RecordJudgement updates the problem and user statistics with one judged submission.
+// It must be called exactly once per judged submission.
+func (r *ProblemRepo) RecordJudgement(ctx context.Context, problemID, userID int, accepted bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.db {
+		if r.db[i].ID == problemID {
+			r.recordJudgement(&r.db[i], userID, accepted)
+			return nil
+		}
+	}
+	return errors.New("problem not found")
+}
+
+// RecomputeStats rebuilds every problem and user counter from the given judged submissions,
+// e.g. after a rejudge changed verdicts.
+func (r *ProblemRepo) RecomputeStats(ctx context.Context, judged []models.SubmissionDB) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := make(map[int]*models.ProblemDB, len(r.db))
+	for i := range r.db {
+		p := &r.db[i]
+		p.TotalSubmissions, p.AcceptedSubmissions, p.SolvedCount, p.AcceptanceRate = 0, 0, 0, 0
+		index[p.ID] = p
+	}
+	r.solvedBy = make(map[int]map[int]bool)
+	r.userStats = make(map[int]*models.UserStats)
+
+	for _, s := range judged {
+		if p, ok := index[s.ProblemID]; ok {
+			r.recordJudgement(p, s.UserID, s.Verdict == "Accepted")
+		}
+	}
+	return nil
+}
+
+// recordJudgement applies one judged submission to the counters. Callers must hold r.mu.
+func (r *ProblemRepo) recordJudgement(p *models.ProblemDB, userID int, accepted bool) {
+	stats, ok := r.userStats[userID]
+	if !ok {
+		stats = &models.UserStats{UserID: userID}
+		r.userStats[userID] = stats
+	}
+
+	p.TotalSubmissions++
+	stats.TotalSubmissions++
+	if accepted {
+		p.AcceptedSubmissions++
+		stats.AcceptedSubmissions++
+
+		if r.solvedBy[userID] == nil {
+			r.solvedBy[userID] = make(map[int]bool)
+		}
+		if !r.solvedBy[userID][p.ID] {
+			r.solvedBy[userID][p.ID] = true
+			p.SolvedCount++
+			stats.SolvedCount++
+		}
+	}
+
+	p.AcceptanceRate = float32(math.Round(float64(p.AcceptedSubmissions)/float64(p.TotalSubmissions)*1000) / 10)
+}
+
+// GetUserStats returns the judging statistics of a user.
+func (r *ProblemRepo) GetUserStats(ctx context.Context, userID int) (*models.UserStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if stats, ok := r.userStats[userID]; ok {
+		result := *stats
+		return &result, nil
+	}
+	return &models.UserStats{UserID: userID}, nil
+}
+
+// GetAllUserStats returns the judging statistics of every user with a judged submission.
+func (r *ProblemRepo) GetAllUserStats(ctx context.Context) ([]models.UserStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := make([]models.UserStats, 0, len(r.userStats))
+	for _, s := range r.userStats {
+		stats = append(stats, *s)
+	}
+	return stats, nil
+}
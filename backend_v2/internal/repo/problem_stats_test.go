@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"online-judge/internal/models"
+)
+
+// judgement is one judged submission of a user for problem 1.
+type judgement struct {
+	userID   int
+	accepted bool
+}
+
+func TestRecordJudgement(t *testing.T) {
+	tests := []struct {
+		name                    string
+		judgements              []judgement
+		total, accepted, solved int
+		rate                    float32
+		user2Solved             int
+	}{
+		{name: "no submissions"},
+		{name: "one wrong", judgements: []judgement{{2, false}}, total: 1},
+		{name: "one accepted", judgements: []judgement{{2, true}}, total: 1, accepted: 1, solved: 1, rate: 100, user2Solved: 1},
+		{
+			name:       "solved twice by one user",
+			judgements: []judgement{{2, false}, {2, true}, {2, true}},
+			total:      3, accepted: 2, solved: 1, rate: 66.7, user2Solved: 1,
+		},
+		{
+			name:       "solved by two users",
+			judgements: []judgement{{2, true}, {3, false}, {3, true}, {4, false}},
+			total:      4, accepted: 2, solved: 2, rate: 50, user2Solved: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewProblemRepo()
+			for _, j := range tt.judgements {
+				if err := r.RecordJudgement(context.Background(), 1, j.userID, j.accepted); err != nil {
+					t.Fatal(err)
+				}
+			}
+			p, err := r.GetProblemMetadata(context.Background(), 1, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.TotalSubmissions != tt.total || p.AcceptedSubmissions != tt.accepted || p.SolvedCount != tt.solved || p.AcceptanceRate != tt.rate {
+				t.Errorf("problem counters = %d/%d/%d %.1f%%, want %d/%d/%d %.1f%%",
+					p.TotalSubmissions, p.AcceptedSubmissions, p.SolvedCount, p.AcceptanceRate,
+					tt.total, tt.accepted, tt.solved, tt.rate)
+			}
+			stats, _ := r.GetUserStats(context.Background(), 2)
+			if stats.SolvedCount != tt.user2Solved {
+				t.Errorf("user 2 solved %d, want %d", stats.SolvedCount, tt.user2Solved)
+			}
+		})
+	}
+
+	if err := NewProblemRepo().RecordJudgement(context.Background(), 99, 2, true); err == nil {
+		t.Error("RecordJudgement of an unknown problem succeeded")
+	}
+}
+
+func TestRecomputeStats(t *testing.T) {
+	r := NewProblemRepo()
+	ctx := context.Background()
+	for _, j := range []judgement{{2, true}, {3, true}, {3, false}} {
+		if err := r.RecordJudgement(ctx, 1, j.userID, j.accepted); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A rejudge turned user 3's accepted submission into a wrong answer.
+	judged := []models.SubmissionDB{
+		{ProblemID: 1, UserID: 2, Verdict: "Accepted"},
+		{ProblemID: 1, UserID: 3, Verdict: "Wrong Answer"},
+		{ProblemID: 1, UserID: 3, Verdict: "Wrong Answer"},
+		{ProblemID: 99, UserID: 3, Verdict: "Accepted"}, // unknown problems are skipped
+	}
+	if err := r.RecomputeStats(ctx, judged); err != nil {
+		t.Fatal(err)
+	}
+
+	p, _ := r.GetProblemMetadata(ctx, 1, false)
+	if p.TotalSubmissions != 3 || p.AcceptedSubmissions != 1 || p.SolvedCount != 1 || p.AcceptanceRate != 33.3 {
+		t.Errorf("problem counters = %d/%d/%d %.1f%%, want 3/1/1 33.3%%",
+			p.TotalSubmissions, p.AcceptedSubmissions, p.SolvedCount, p.AcceptanceRate)
+	}
+	tests := []struct {
+		userID, total, solved int
+	}{
+		{2, 1, 1},
+		{3, 2, 0},
+	}
+	for _, tt := range tests {
+		stats, _ := r.GetUserStats(ctx, tt.userID)
+		if stats.TotalSubmissions != tt.total || stats.SolvedCount != tt.solved {
+			t.Errorf("user %d = %d total %d solved, want %d %d", tt.userID, stats.TotalSubmissions, stats.SolvedCount, tt.total, tt.solved)
+		}
+	}
+}
+
+func TestProblemWritesKeepStatistics(t *testing.T) {
+	ctx := context.Background()
+	r := NewProblemRepo()
+	for _, j := range []judgement{{2, true}, {3, false}} {
+		if err := r.RecordJudgement(ctx, 1, j.userID, j.accepted); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	forged := models.ProblemDB{TotalSubmissions: 1000, AcceptedSubmissions: 1000, SolvedCount: 1000, AcceptanceRate: 100}
+
+	update := forged
+	update.ID, update.Title = 1, "Renamed"
+	if err := r.UpdateProblemByID(ctx, &update, 1); err != nil {
+		t.Fatal(err)
+	}
+	create := forged
+	create.Title = "New"
+	id, err := r.CreateProblem(ctx, &create)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id                      int
+		total, accepted, solved int
+		rate                    float32
+	}{
+		{1, 2, 1, 1, 50},
+		{id, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		p, err := r.GetProblemMetadata(ctx, tt.id, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.TotalSubmissions != tt.total || p.AcceptedSubmissions != tt.accepted || p.SolvedCount != tt.solved || p.AcceptanceRate != tt.rate {
+			t.Errorf("problem %d counters = %d/%d/%d %.1f%%, want %d/%d/%d %.1f%%", tt.id,
+				p.TotalSubmissions, p.AcceptedSubmissions, p.SolvedCount, p.AcceptanceRate,
+				tt.total, tt.accepted, tt.solved, tt.rate)
+		}
+	}
+}
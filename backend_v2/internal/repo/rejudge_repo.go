@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"online-judge/internal/models"
+	"sync"
+	"time"
+)
+
+// RejudgeRepo keeps track of rejudge requests and the verdicts they changed.
+type RejudgeRepo struct {
+	mu        sync.RWMutex
+	db        []models.RejudgeDB
+	pendingBy map[int]int // submission ID -> ID of the rejudge waiting for its verdict
+}
+
+func NewRejudgeRepo() *RejudgeRepo {
+	return &RejudgeRepo{
+		db:        make([]models.RejudgeDB, 0),
+		pendingBy: make(map[int]int),
+	}
+}
+
+// NewRejudge records a rejudge of the given submissions, as they were before the rejudge,
+// and returns its ID. A submission belongs to at most one pending rejudge; a newer
+// rejudge takes it over.
+func (r *RejudgeRepo) NewRejudge(ctx context.Context, actorID int, filter models.RejudgeFilter, previous []models.SubmissionDB) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rejudge := models.RejudgeDB{
+		ID:            len(r.db) + 1,
+		ActorID:       actorID,
+		Filter:        filter,
+		SubmissionIDs: make([]int, 0, len(previous)),
+		Changes:       []models.VerdictChange{},
+		Previous:      make(map[int]models.SubmissionDB, len(previous)),
+		CreatedAt:     time.Now(),
+	}
+	for _, s := range previous {
+		if id, ok := r.pendingBy[s.ID]; ok {
+			r.complete(&r.db[id-1], s.ID)
+		}
+		r.pendingBy[s.ID] = rejudge.ID
+		rejudge.SubmissionIDs = append(rejudge.SubmissionIDs, s.ID)
+		rejudge.Previous[s.ID] = s
+	}
+	rejudge.Pending = len(rejudge.SubmissionIDs)
+
+	r.db = append(r.db, rejudge)
+	return rejudge.ID, nil
+}
+
+// RecordResult stores the new verdict of a rejudged submission. It returns the ID of the
+// rejudge the submission belonged to and whether that rejudge is now complete.
+func (r *RejudgeRepo) RecordResult(ctx context.Context, submissionID int, verdict, status string) (int, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := r.pendingBy[submissionID]
+	if !ok {
+		return 0, false, errors.New("submission is not being rejudged")
+	}
+	rejudge := &r.db[id-1]
+
+	old := rejudge.Previous[submissionID]
+	if old.Verdict != verdict {
+		rejudge.Changes = append(rejudge.Changes, models.VerdictChange{
+			SubmissionID: submissionID,
+			UserID:       old.UserID,
+			ProblemID:    old.ProblemID,
+			OldVerdict:   old.Verdict,
+			NewVerdict:   verdict,
+			OldStatus:    old.Status,
+			NewStatus:    status,
+		})
+	}
+	r.complete(rejudge, submissionID)
+	return rejudge.ID, rejudge.CompletedAt != nil, nil
+}
+
+// complete marks one submission of a rejudge as done. Callers must hold r.mu.
+func (r *RejudgeRepo) complete(rejudge *models.RejudgeDB, submissionID int) {
+	delete(r.pendingBy, submissionID)
+	rejudge.Pending--
+	if rejudge.Pending == 0 {
+		now := time.Now()
+		rejudge.CompletedAt = &now
+	}
+}
+
+func (r *RejudgeRepo) GetRejudge(ctx context.Context, id int) (*models.RejudgeDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if id < 1 || id > len(r.db) {
+		return nil, errors.New("rejudge not found")
+	}
+	rejudge := r.db[id-1]
+	rejudge.Changes = append([]models.VerdictChange{}, rejudge.Changes...)
+	return &rejudge, nil
+}
@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"online-judge/internal/models"
+)
+
+// rejudgeResult is a new verdict reported for a rejudged submission.
+type rejudgeResult struct {
+	submissionID int
+	verdict      string
+}
+
+func TestRejudgeRecordResult(t *testing.T) {
+	previous := []models.SubmissionDB{
+		{ID: 1, UserID: 2, ProblemID: 1, Verdict: "Accepted"},
+		{ID: 2, UserID: 3, ProblemID: 1, Verdict: "Wrong Answer"},
+	}
+
+	tests := []struct {
+		name      string
+		results   []rejudgeResult
+		changed   []int // IDs of the submissions whose verdict changed, in order
+		completed bool
+		wantErr   bool
+	}{
+		{name: "nothing reported"},
+		{name: "one of two reported", results: []rejudgeResult{{1, "Accepted"}}},
+		{name: "unchanged", results: []rejudgeResult{{1, "Accepted"}, {2, "Wrong Answer"}}, completed: true},
+		{
+			name:      "changed",
+			results:   []rejudgeResult{{2, "Accepted"}, {1, "TLE"}},
+			changed:   []int{2, 1},
+			completed: true,
+		},
+		{name: "reported twice", results: []rejudgeResult{{1, "Accepted"}, {1, "TLE"}}, wantErr: true},
+		{name: "not rejudged", results: []rejudgeResult{{3, "Accepted"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRejudgeRepo()
+			ctx := context.Background()
+			id, err := r.NewRejudge(ctx, 1, models.RejudgeFilter{ProblemID: 1}, previous)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var lastErr error
+			for _, res := range tt.results {
+				if _, _, err := r.RecordResult(ctx, res.submissionID, res.verdict, res.verdict); err != nil {
+					lastErr = err
+				}
+			}
+			if (lastErr != nil) != tt.wantErr {
+				t.Fatalf("RecordResult error = %v, want error %v", lastErr, tt.wantErr)
+			}
+
+			rejudge, err := r.GetRejudge(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (rejudge.CompletedAt != nil) != tt.completed {
+				t.Errorf("completed = %v, want %v", rejudge.CompletedAt != nil, tt.completed)
+			}
+			if len(rejudge.Changes) != len(tt.changed) {
+				t.Fatalf("%d changes, want %d", len(rejudge.Changes), len(tt.changed))
+			}
+			for i, c := range rejudge.Changes {
+				old := previous[c.SubmissionID-1]
+				if c.SubmissionID != tt.changed[i] || c.OldVerdict != old.Verdict || c.UserID != old.UserID {
+					t.Errorf("change %d = %+v, want submission %d from %q", i, c, tt.changed[i], old.Verdict)
+				}
+			}
+		})
+	}
+}
+
+func TestNewerRejudgeTakesOverSubmissions(t *testing.T) {
+	r := NewRejudgeRepo()
+	ctx := context.Background()
+	previous := []models.SubmissionDB{{ID: 1, Verdict: "Accepted"}}
+
+	first, _ := r.NewRejudge(ctx, 1, models.RejudgeFilter{SubmissionID: 1}, previous)
+	second, _ := r.NewRejudge(ctx, 1, models.RejudgeFilter{SubmissionID: 1}, previous)
+
+	if rejudge, _ := r.GetRejudge(ctx, first); rejudge.CompletedAt == nil {
+		t.Error("the first rejudge is still pending after being taken over")
+	}
+	id, completed, err := r.RecordResult(ctx, 1, "TLE", "TLE")
+	if err != nil || id != second || !completed {
+		t.Errorf("RecordResult = %d, %v, %v, want %d, true, nil", id, completed, err, second)
+	}
+}
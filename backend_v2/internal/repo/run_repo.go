@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"online-judge/internal/models"
+	"sync"
+	"time"
+)
+
+// RunRepo keeps the state of "Run" requests. Runs are never recorded as submissions.
+type RunRepo struct {
+	mu sync.RWMutex
+	db []models.RunDB
+}
+
+func NewRunRepo() *RunRepo {
+	return &RunRepo{db: make([]models.RunDB, 0)}
+}
+
+// NewRun stores a pending run and returns its ID
+func (r *RunRepo) NewRun(ctx context.Context, run models.RunDB) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	run.ID = len(r.db) + 1
+	run.Status = "pending"
+	run.CreatedAt = time.Now()
+	r.db = append(r.db, run)
+	return run.ID, nil
+}
+
+// GetRun retrieves a run by ID, pending or not
+func (r *RunRepo) GetRun(ctx context.Context, runId int) (*models.RunDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := range r.db {
+		if r.db[i].ID == runId {
+			run := r.db[i]
+			return &run, nil
+		}
+	}
+	return nil, errors.New("run not found")
+}
+
+// CompleteRun stores the final status and per-test results of a run
+func (r *RunRepo) CompleteRun(ctx context.Context, runId int, status string, results []models.TestCaseResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.db {
+		if r.db[i].ID == runId {
+			r.db[i].Status = status
+			r.db[i].TestCaseResults = results
+			return nil
+		}
+	}
+	return errors.New("run not found")
+}
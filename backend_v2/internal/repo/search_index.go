@@ -0,0 +1,318 @@
+package repo
+
+import (
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Weights given to a term occurrence depending on where it appears; they mirror the
+// default weights Postgres uses for setweight 'A' (title) and 'B' (description).
+const (
+	titleTermWeight       = 1.0
+	descriptionTermWeight = 0.4
+	snippetWords          = 30
+	snippetLeadWords      = 8
+	highlightStart        = "<mark>"
+	highlightStop         = "</mark>"
+)
+
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "to": true, "with": true,
+}
+
+// searchIndex is an in-memory inverted index over problem titles and descriptions.
+// It is not safe for concurrent use; ProblemRepo guards it with its own mutex.
+type searchIndex struct {
+	postings  map[string]map[int]float64 // term -> problem ID -> weighted term frequency
+	positions map[int]map[string][]int   // problem ID -> term -> word positions, for phrases
+}
+
+type searchMatch struct {
+	problemID int
+	rank      float64
+}
+
+// queryClause is a word or a quoted phrase of a search query.
+type queryClause struct {
+	terms   []string // stemmed terms; "" keeps the place of a stop word inside a phrase
+	negated bool
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		postings:  make(map[string]map[int]float64),
+		positions: make(map[int]map[string][]int),
+	}
+}
+
+// stem reduces a lower-cased word to a crude root so "edges" matches "edge"
+// and "sorting" matches "sort".
+func stem(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 5 && strings.HasSuffix(word, "ing"):
+		return word[:len(word)-3]
+	case len(word) > 4 && strings.HasSuffix(word, "ed"):
+		return word[:len(word)-2]
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// tokenize splits text into stemmed search terms, one per word. Stop words are kept as ""
+// so that the index of a term is its word position.
+func tokenize(text string) []string {
+	words := wordPattern.FindAllString(strings.ToLower(text), -1)
+	terms := make([]string, len(words))
+	for i, word := range words {
+		if !stopWords[word] {
+			terms[i] = stem(word)
+		}
+	}
+	return terms
+}
+
+// parseQuery reads a query the way websearch_to_tsquery does: clauses are ANDed, "or"
+// between two clauses ORs them, a leading "-" negates a clause and quoted text is a
+// phrase. The result is a conjunction of disjunctions. Clauses made only of stop words
+// are dropped.
+func parseQuery(query string) [][]queryClause {
+	var groups [][]queryClause
+	or, negated := false, false
+	for i := 0; i < len(query); {
+		var text string
+		switch c := query[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+			continue
+		case c == '-' && !negated && i+1 < len(query) && query[i+1] != ' ':
+			negated = true
+			i++
+			continue
+		case c == '"':
+			end := strings.IndexByte(query[i+1:], '"')
+			if end < 0 {
+				end = len(query) - i - 1
+			}
+			text = query[i+1 : i+1+end]
+			i += end + 2
+		default:
+			end := strings.IndexAny(query[i:], " \t\n\"")
+			if end < 0 {
+				end = len(query) - i
+			}
+			text = query[i : i+end]
+			i += end
+			if strings.EqualFold(text, "or") && !negated {
+				or = len(groups) > 0
+				continue
+			}
+		}
+
+		clause := queryClause{terms: tokenize(text), negated: negated}
+		negated = false
+		if strings.Join(clause.terms, "") == "" {
+			continue
+		}
+		if or {
+			groups[len(groups)-1] = append(groups[len(groups)-1], clause)
+		} else {
+			groups = append(groups, []queryClause{clause})
+		}
+		or = false
+	}
+	return groups
+}
+
+// Index adds or replaces the document of a problem. The description is indexed after
+// the title, so word positions run on from one to the other.
+func (idx *searchIndex) Index(problemID int, title, description string) {
+	idx.Remove(problemID)
+
+	titleTerms := tokenize(title)
+	weights := make(map[string]float64)
+	positions := make(map[string][]int)
+	for i, term := range append(titleTerms, tokenize(description)...) {
+		if term == "" {
+			continue
+		}
+		if i < len(titleTerms) {
+			weights[term] += titleTermWeight
+		} else {
+			weights[term] += descriptionTermWeight
+		}
+		positions[term] = append(positions[term], i)
+	}
+
+	for term, weight := range weights {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[int]float64)
+		}
+		idx.postings[term][problemID] = weight
+	}
+	idx.positions[problemID] = positions
+}
+
+// Remove drops a problem from the index.
+func (idx *searchIndex) Remove(problemID int) {
+	for term := range idx.positions[problemID] {
+		delete(idx.postings[term], problemID)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.positions, problemID)
+}
+
+// Search returns the problems matching a query, best match first; see parseQuery for the
+// syntax. Documents are ranked by TF-IDF using the weighted term frequencies of the
+// clauses they match.
+func (idx *searchIndex) Search(query string) []searchMatch {
+	groups := parseQuery(query)
+	if len(groups) == 0 {
+		return nil
+	}
+
+	var matches []searchMatch
+	for problemID := range idx.positions {
+		rank, ok := 0.0, true
+		for _, group := range groups {
+			matched := false
+			for _, clause := range group {
+				if idx.contains(problemID, clause.terms) == clause.negated {
+					continue
+				}
+				matched = true
+				if !clause.negated {
+					rank += idx.rank(problemID, clause.terms)
+				}
+			}
+			if !matched {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matches = append(matches, searchMatch{problemID: problemID, rank: rank})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank > matches[j].rank
+		}
+		return matches[i].problemID < matches[j].problemID
+	})
+	return matches
+}
+
+// contains reports whether a document has the terms as a phrase, in order and at the same
+// distances from each other.
+func (idx *searchIndex) contains(problemID int, terms []string) bool {
+	positions := idx.positions[problemID]
+	first := -1
+	for i, term := range terms {
+		if term != "" {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return false
+	}
+
+next:
+	for _, start := range positions[terms[first]] {
+		for i, term := range terms[first+1:] {
+			if term != "" && !hasPosition(positions[term], start+i+1) {
+				continue next
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func hasPosition(positions []int, want int) bool {
+	i := sort.SearchInts(positions, want)
+	return i < len(positions) && positions[i] == want
+}
+
+// rank sums the TF-IDF of the terms in a document.
+func (idx *searchIndex) rank(problemID int, terms []string) float64 {
+	rank := 0.0
+	for _, term := range terms {
+		if posting := idx.postings[term]; term != "" && len(posting) > 0 {
+			idf := math.Log(1 + float64(len(idx.positions))/float64(len(posting)))
+			rank += posting[problemID] * idf
+		}
+	}
+	return rank
+}
+
+// highlight HTML-escapes text and wraps every word matching a term of the query that is
+// not negated in <mark> tags. When window is true only a fragment of about snippetWords
+// words around the first match is returned, similar to what ts_headline produces.
+func highlight(text, query string, window bool) string {
+	wanted := make(map[string]bool)
+	for _, group := range parseQuery(query) {
+		for _, clause := range group {
+			for _, term := range clause.terms {
+				if term != "" && !clause.negated {
+					wanted[term] = true
+				}
+			}
+		}
+	}
+
+	words := wordPattern.FindAllStringIndex(text, -1)
+	if len(words) == 0 {
+		return html.EscapeString(text)
+	}
+
+	first := 0
+	for i, w := range words {
+		if wanted[stem(strings.ToLower(text[w[0]:w[1]]))] {
+			first = i
+			break
+		}
+	}
+
+	from, to := 0, len(words)
+	if window {
+		from = max(first-snippetLeadWords, 0)
+		to = min(from+snippetWords, len(words))
+	}
+
+	var b strings.Builder
+	pos := 0
+	if from > 0 {
+		b.WriteString("… ")
+		pos = words[from][0]
+	}
+	for _, w := range words[from:to] {
+		b.WriteString(html.EscapeString(text[pos:w[0]]))
+		word := html.EscapeString(text[w[0]:w[1]])
+		if wanted[stem(strings.ToLower(text[w[0]:w[1]]))] {
+			b.WriteString(highlightStart + word + highlightStop)
+		} else {
+			b.WriteString(word)
+		}
+		pos = w[1]
+	}
+	if to < len(words) {
+		b.WriteString(" …")
+	} else {
+		b.WriteString(html.EscapeString(text[pos:]))
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"Sorting the edges", []string{"sort", "", "edge"}},
+		{"Two-Sum, of arrays!", []string{"two", "sum", "", "array"}},
+		{"Queries and cities", []string{"query", "", "city"}},
+		{"class is passed", []string{"class", "", "pass"}},
+	}
+	for _, tt := range tests {
+		if got := tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	idx := newSearchIndex()
+	idx.Index(1, "Two Sum", "Find two numbers in an array that add up to a target.")
+	idx.Index(2, "Shortest Path", "Find the shortest path between two nodes of a weighted graph.")
+	idx.Index(3, "Sort Colors", "Sort an array of colors in place.")
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"", nil},
+		{"the of", nil},
+		{"array", []int{1, 3}},
+		{"two array", []int{1}},
+		{"sum or colors", []int{3, 1}},
+		{"array -colors", []int{1}},
+		{"-array", []int{2}},
+		{`"shortest path"`, []int{2}},
+		{`"path shortest"`, nil},
+		{`"two numbers in array"`, nil},
+		{`"two numbers in an array"`, []int{1}},
+		{`find -"two numbers"`, []int{2}},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, m := range idx.Search(tt.query) {
+			got = append(got, m.problemID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	idx.Remove(3)
+	if got := idx.Search("colors"); len(got) != 0 {
+		t.Errorf("Search after Remove = %v, want no matches", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text, query string
+		want        string
+	}{
+		{"Two Sum", "sum", "Two <mark>Sum</mark>"},
+		{"Sort <b>colors</b> & more", "colors", "Sort &lt;b&gt;<mark>colors</mark>&lt;/b&gt; &amp; more"},
+		{"<script>", "x", "&lt;script&gt;"},
+		{"Sort colors", "sort -colors", "<mark>Sort</mark> colors"},
+	}
+	for _, tt := range tests {
+		if got := highlight(tt.text, tt.query, false); got != tt.want {
+			t.Errorf("highlight(%q, %q) = %q, want %q", tt.text, tt.query, got, tt.want)
+		}
+	}
+}
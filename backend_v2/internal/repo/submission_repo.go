@@ -3,12 +3,22 @@ package repo
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"math"
 	"online-judge/internal/models"
-	// "sync" // Uncomment if thread safety is needed
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
+// ErrTooManyPending is returned by NewSubmission when the user already has the maximum
+// number of submissions waiting for a verdict.
+var ErrTooManyPending = errors.New("too many pending submissions")
+
 type SubmissionRepo struct {
+	mu sync.RWMutex
 	db []models.SubmissionDB
 }
 
@@ -16,50 +26,288 @@ func NewSubmissionRepo() *SubmissionRepo {
 	return &SubmissionRepo{db: make([]models.SubmissionDB, 0)}
 }
 
-// Creates a new submission and appends it to the DB
-func (r *SubmissionRepo) NewSubmission(ctx context.Context, problemId, userId int) (int, error) {
-	submission := models.SubmissionDB{
-		ID:        len(r.db) + 1,
-		Status:    "pending",
-		UserID:    userId,
-		ProblemID: problemId,
-		RuntimeMS: 0,
-		MemoryKB:  0,
+// Creates a new queued submission from the user, problem, language, code and contest
+// fields of the given one and appends it to the DB
+func (r *SubmissionRepo) NewSubmission(ctx context.Context, submission models.SubmissionDB, maxPending int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if maxPending > 0 && r.countPending(submission.UserID) >= maxPending {
+		return 0, ErrTooManyPending
 	}
+
+	now := time.Now()
+	submission.ID = len(r.db) + 1
+	submission.Status = "pending"
+	submission.State = models.SubmissionStateQueued
+	submission.CreatedAt = now
+	submission.QueuedAt = &now
 	r.db = append(r.db, submission)
 	return submission.ID, nil
 }
 
-// Retrieves a submission by ID (only if not pending)
-func (r *SubmissionRepo) GetSubmission(ctx context.Context, submissionId int) (*models.SubmissionDB, error) {
-	log.Println("\n\n Submissions:", r.db)
+// Retrieves a submission by ID (only if not pending, unless includePending is set)
+func (r *SubmissionRepo) GetSubmission(ctx context.Context, submissionId int, includePending bool) (*models.SubmissionDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
 	for i := range r.db {
 		if r.db[i].ID == submissionId {
-			if r.db[i].Status == "pending" {
-				log.Println("\nSubmission found. Status:", r.db[i].Status, r.db[i].MemoryKB, r.db[i].RuntimeMS)
+			if !r.db[i].State.IsFinal() && !includePending {
 				return nil, errors.New("submission is still pending")
 			}
-			return &r.db[i], nil // FIX: return pointer to actual element
+			submission := r.db[i]
+			return &submission, nil
 		}
 	}
 	return nil, errors.New("submission not found")
 }
 
-// Updates the runtime, memory, and status of a submission
-func (r *SubmissionRepo) UpdateSubmission(ctx context.Context, submissionId, runtime, memory int, status string) error {
-	log.Println("\n\nUpdate Submission request received:", submissionId, runtime, memory, status)
-	log.Println("Existing submissions: ", r.db)
+// Stores the verdict, resource usage and per-test results of a judged (or failed) submission
+func (r *SubmissionRepo) UpdateSubmission(ctx context.Context, submissionId int, judgement models.SubmissionJudgement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log.Println("Update Submission request received:", submissionId, judgement.RuntimeMS, judgement.MemoryKB, judgement.Status)
 
 	for i := range r.db {
-		log.Println("Checking: ", r.db[i].ID, submissionId)
 		if r.db[i].ID == submissionId {
-			log.Println("\nSubmission found. Updating...")
-			r.db[i].RuntimeMS = runtime
-			r.db[i].MemoryKB = memory
-			r.db[i].Status = status
+			s := &r.db[i]
+			if !s.State.CanTransitionTo(judgement.State) {
+				return fmt.Errorf("invalid state transition from %q to %q", s.State, judgement.State)
+			}
+
+			now := time.Now()
+			s.State = judgement.State
+			s.RuntimeMS = judgement.RuntimeMS
+			s.MemoryKB = judgement.MemoryKB
+			s.Status = judgement.Status
+			s.Verdict = judgement.Verdict
+			s.TestCaseResults = judgement.TestCaseResults
+			s.FailedTest = judgement.FailedTest
+			s.JudgedAt = &now
+			if judgement.WorkerID != "" {
+				s.WorkerID = judgement.WorkerID
+			}
+			if s.StartedAt == nil && !judgement.StartedAt.IsZero() {
+				s.StartedAt = &judgement.StartedAt
+			}
+			s.FinishedAt = &now
+			if !judgement.FinishedAt.IsZero() {
+				s.FinishedAt = &judgement.FinishedAt
+			}
+			return nil
+		}
+	}
+	return errors.New("submission not found")
+}
+
+// UpdateSubmissionState records a lifecycle transition reported by a worker
+func (r *SubmissionRepo) UpdateSubmissionState(ctx context.Context, submissionId int, state models.SubmissionState, workerID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.db {
+		if r.db[i].ID == submissionId {
+			s := &r.db[i]
+			if !s.State.CanTransitionTo(state) {
+				return fmt.Errorf("invalid state transition from %q to %q", s.State, state)
+			}
+			s.State = state
+			if workerID != "" {
+				s.WorkerID = workerID
+			}
+			if s.StartedAt == nil && (state == models.SubmissionStateCompiling || state == models.SubmissionStateRunning) {
+				s.StartedAt = &at
+			}
+			if state.IsFinal() {
+				s.FinishedAt = &at
+			}
 			return nil
 		}
 	}
 	return errors.New("submission not found")
 }
+
+// GetJudgeCapacity returns the average time spent judging a submission and the number of
+// distinct workers that finished one within the window. Both are zero without data.
+func (r *SubmissionRepo) GetJudgeCapacity(ctx context.Context, window time.Duration) (time.Duration, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	since := time.Now().Add(-window)
+	var total time.Duration
+	count := 0
+	workers := make(map[string]bool)
+	for _, s := range r.db {
+		if s.StartedAt == nil || s.FinishedAt == nil || s.FinishedAt.Before(since) {
+			continue
+		}
+		total += s.FinishedAt.Sub(*s.StartedAt)
+		count++
+		if s.WorkerID != "" {
+			workers[s.WorkerID] = true
+		}
+	}
+	if count == 0 {
+		return 0, 0, nil
+	}
+	return total / time.Duration(count), len(workers), nil
+}
+
+// GetQueueMetrics summarises the submissions queued within the given window
+func (r *SubmissionRepo) GetQueueMetrics(ctx context.Context, window time.Duration) (*models.QueueMetrics, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	since := time.Now().Add(-window)
+	metrics := &models.QueueMetrics{
+		WindowSeconds: int(window.Seconds()),
+		States:        make(map[models.SubmissionState]int),
+	}
+
+	var waits, durations []float64
+	for _, s := range r.db {
+		if s.QueuedAt == nil || s.QueuedAt.Before(since) {
+			continue
+		}
+		metrics.States[s.State]++
+		if s.StartedAt != nil {
+			waits = append(waits, float64(s.StartedAt.Sub(*s.QueuedAt).Milliseconds()))
+			if s.FinishedAt != nil {
+				durations = append(durations, float64(s.FinishedAt.Sub(*s.StartedAt).Milliseconds()))
+			}
+		}
+	}
+
+	metrics.QueueWait = durationStats(waits)
+	metrics.JudgeDuration = durationStats(durations)
+	return metrics, nil
+}
+
+// durationStats computes nearest-rank percentiles of durations in milliseconds
+func durationStats(values []float64) models.DurationStats {
+	if len(values) == 0 {
+		return models.DurationStats{}
+	}
+	sort.Float64s(values)
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p/100*float64(len(values)))) - 1
+		return values[max(rank, 0)]
+	}
+	return models.DurationStats{
+		Count: len(values),
+		P50MS: percentile(50),
+		P95MS: percentile(95),
+		MaxMS: values[len(values)-1],
+	}
+}
+
+// ListSubmissions returns a page of a user's submissions matching the query, newest first
+func (r *SubmissionRepo) ListSubmissions(ctx context.Context, query models.SubmissionListQuery) (*models.SubmissionListPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []models.SubmissionDB
+	for i := len(r.db) - 1; i >= 0; i-- {
+		s := r.db[i]
+		if s.UserID != query.UserID ||
+			(query.ProblemID != 0 && s.ProblemID != query.ProblemID) ||
+			(query.LanguageID != 0 && s.LanguageID != query.LanguageID) ||
+			(query.ContestID != 0 && s.ContestID != query.ContestID) ||
+			(query.Verdict != "" && !strings.EqualFold(s.Verdict, query.Verdict)) {
+			continue
+		}
+		matched = append(matched, s)
+	}
+
+	page := &models.SubmissionListPage{
+		Submissions: []models.SubmissionDB{},
+		Total:       len(matched),
+		Page:        query.Page,
+		PageSize:    query.PageSize,
+		TotalPages:  (len(matched) + query.PageSize - 1) / query.PageSize,
+	}
+	start := min((query.Page-1)*query.PageSize, len(matched))
+	end := min(start+query.PageSize, len(matched))
+	page.Submissions = append(page.Submissions, matched[start:end]...)
+	return page, nil
+}
+
+// ResetForRejudge moves every judged or failed submission selected by filter back to the
+// Queued state and returns them as they were before the reset. Submissions still being
+// judged are left alone.
+func (r *SubmissionRepo) ResetForRejudge(ctx context.Context, filter models.RejudgeFilter) ([]models.SubmissionDB, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var previous []models.SubmissionDB
+	for i := range r.db {
+		s := &r.db[i]
+		if !s.State.IsFinal() || !filter.Matches(*s) {
+			continue
+		}
+		previous = append(previous, *s)
+
+		s.State = models.SubmissionStateQueued
+		s.Status = "pending"
+		s.Verdict = ""
+		s.FailedTest = nil
+		s.QueuedAt = &now
+		s.StartedAt = nil
+		s.FinishedAt = nil
+	}
+	if len(previous) == 0 {
+		return nil, errors.New("no judged submissions match")
+	}
+	return previous, nil
+}
+
+// GetJudgedSubmissions returns every submission with a verdict, oldest first
+func (r *SubmissionRepo) GetJudgedSubmissions(ctx context.Context) ([]models.SubmissionDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var judged []models.SubmissionDB
+	for _, s := range r.db {
+		if s.State == models.SubmissionStateJudged {
+			judged = append(judged, s)
+		}
+	}
+	return judged, nil
+}
+
+// CountPending returns how many submissions of a user are waiting for their first verdict.
+// Submissions being rejudged are not counted.
+func (r *SubmissionRepo) CountPending(ctx context.Context, userID int) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.countPending(userID), nil
+}
+
+// countPending implements CountPending. Callers must hold r.mu.
+func (r *SubmissionRepo) countPending(userID int) int {
+	count := 0
+	for _, s := range r.db {
+		if s.UserID == userID && s.JudgedAt == nil && !s.State.IsFinal() {
+			count++
+		}
+	}
+	return count
+}
+
+// GetUserSubmissions returns every submission of a user, oldest first
+func (r *SubmissionRepo) GetUserSubmissions(ctx context.Context, userID int) ([]models.SubmissionDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var submissions []models.SubmissionDB
+	for _, s := range r.db {
+		if s.UserID == userID {
+			submissions = append(submissions, s)
+		}
+	}
+	return submissions, nil
+}
@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"online-judge/internal/models"
+)
+
+func TestNewSubmissionPendingLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxPending int
+		queued     int // submissions of user 2 still waiting for a verdict
+		judged     int // submissions of user 2 with a verdict
+		others     int // submissions of user 3 still waiting for a verdict
+		wantErr    bool
+	}{
+		{name: "unlimited", maxPending: 0, queued: 10},
+		{name: "below the limit", maxPending: 3, queued: 2},
+		{name: "at the limit", maxPending: 3, queued: 3, wantErr: true},
+		{name: "judged submissions do not count", maxPending: 3, queued: 2, judged: 5},
+		{name: "other users do not count", maxPending: 3, queued: 2, others: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSubmissionRepo()
+			ctx := context.Background()
+			for i := 0; i < tt.judged; i++ {
+				id, _ := r.NewSubmission(ctx, models.SubmissionDB{UserID: 2}, 0)
+				if err := r.UpdateSubmission(ctx, id, models.SubmissionJudgement{State: models.SubmissionStateJudged}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for i := 0; i < tt.queued; i++ {
+				r.NewSubmission(ctx, models.SubmissionDB{UserID: 2}, 0)
+			}
+			for i := 0; i < tt.others; i++ {
+				r.NewSubmission(ctx, models.SubmissionDB{UserID: 3}, 0)
+			}
+
+			_, err := r.NewSubmission(ctx, models.SubmissionDB{UserID: 2}, tt.maxPending)
+			if tt.wantErr != errors.Is(err, ErrTooManyPending) {
+				t.Errorf("NewSubmission error = %v, want ErrTooManyPending %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// judgedRun is a submission judged by a worker, finished some time ago.
+type judgedRun struct {
+	workerID string
+	took     time.Duration
+	ago      time.Duration
+}
+
+func TestGetJudgeCapacity(t *testing.T) {
+	tests := []struct {
+		name    string
+		runs    []judgedRun
+		avg     time.Duration
+		workers int
+	}{
+		{name: "no data"},
+		{
+			name:    "one worker",
+			runs:    []judgedRun{{"w1", time.Second, time.Minute}, {"w1", 3 * time.Second, time.Minute}},
+			avg:     2 * time.Second,
+			workers: 1,
+		},
+		{
+			name:    "two workers",
+			runs:    []judgedRun{{"w1", time.Second, time.Minute}, {"w2", time.Second, time.Minute}},
+			avg:     time.Second,
+			workers: 2,
+		},
+		{
+			name:    "outside the window",
+			runs:    []judgedRun{{"w1", time.Second, time.Minute}, {"w2", 9 * time.Second, time.Hour}},
+			avg:     time.Second,
+			workers: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSubmissionRepo()
+			ctx := context.Background()
+			for _, run := range tt.runs {
+				id, _ := r.NewSubmission(ctx, models.SubmissionDB{UserID: 2}, 0)
+				finished := time.Now().Add(-run.ago)
+				if err := r.UpdateSubmission(ctx, id, models.SubmissionJudgement{
+					State:      models.SubmissionStateJudged,
+					WorkerID:   run.workerID,
+					StartedAt:  finished.Add(-run.took),
+					FinishedAt: finished,
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			avg, workers, err := r.GetJudgeCapacity(ctx, 10*time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if avg != tt.avg || workers != tt.workers {
+				t.Errorf("GetJudgeCapacity = %v, %d, want %v, %d", avg, workers, tt.avg, tt.workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package repo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"online-judge/internal/models"
+	"sync"
+	"time"
+)
+
+// TokenRepo stores personal access tokens by the hash of their value.
+type TokenRepo struct {
+	mu     sync.RWMutex
+	db     []models.PersonalAccessTokenDB
+	byHash map[string]int // token hash -> index in db
+}
+
+func NewTokenRepo() *TokenRepo {
+	return &TokenRepo{
+		db:     make([]models.PersonalAccessTokenDB, 0),
+		byHash: make(map[string]int),
+	}
+}
+
+// HashToken returns the hex SHA-256 of a token value. Tokens are long random strings, so
+// an unsalted fast hash is enough to keep them unusable if the store leaks.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (r *TokenRepo) CreateToken(ctx context.Context, token models.PersonalAccessTokenDB) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byHash[token.Hash]; ok {
+		return 0, errors.New("token already exists")
+	}
+	token.ID = len(r.db) + 1
+	token.Scopes = append([]string(nil), token.Scopes...)
+	token.CreatedAt = time.Now()
+	r.byHash[token.Hash] = len(r.db)
+	r.db = append(r.db, token)
+	return token.ID, nil
+}
+
+// ListTokens returns the tokens of a user, revoked and expired ones included.
+func (r *TokenRepo) ListTokens(ctx context.Context, userID int) ([]models.PersonalAccessTokenDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tokens := make([]models.PersonalAccessTokenDB, 0)
+	for _, t := range r.db {
+		if t.UserID == userID {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *TokenRepo) RevokeToken(ctx context.Context, userID, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.db) || r.db[id-1].UserID != userID {
+		return errors.New("token not found")
+	}
+	if r.db[id-1].RevokedAt == nil {
+		now := time.Now()
+		r.db[id-1].RevokedAt = &now
+	}
+	return nil
+}
+
+// Authenticate returns the token with the given value if it is neither revoked nor
+// expired, and records its use.
+func (r *TokenRepo) Authenticate(ctx context.Context, token string, now time.Time) (models.PersonalAccessTokenDB, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.byHash[HashToken(token)]
+	if !ok {
+		return models.PersonalAccessTokenDB{}, errors.New("unknown token")
+	}
+	t := &r.db[i]
+	if t.RevokedAt != nil {
+		return models.PersonalAccessTokenDB{}, errors.New("token revoked")
+	}
+	if !now.Before(t.ExpiresAt) {
+		return models.PersonalAccessTokenDB{}, errors.New("token expired")
+	}
+	t.LastUsedAt = &now
+	return *t, nil
+}
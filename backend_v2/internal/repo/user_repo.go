@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"online-judge/internal/models"
+	"strings"
+	"sync"
+)
+
+type UserRepo struct {
+	mu sync.RWMutex
+	db []models.UserDB
+}
+
+// NewUserRepo returns a repo holding the default administrator, who owns the mock data.
+// It has no password, so it cannot log in until SetPassword is called.
+func NewUserRepo() *UserRepo {
+	return &UserRepo{db: []models.UserDB{
+		{ID: 1, Username: "some", Email: "some@one.in", IsAdmin: true, IsActive: true},
+	}}
+}
+
+// CreateUser adds an active user and returns its ID. Usernames and emails are unique,
+// case-insensitively.
+func (r *UserRepo) CreateUser(ctx context.Context, user models.UserDB) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.db {
+		if strings.EqualFold(u.Username, user.Username) {
+			return 0, errors.New("username is taken")
+		}
+		if strings.EqualFold(u.Email, user.Email) {
+			return 0, errors.New("email is already registered")
+		}
+	}
+	user.ID = len(r.db) + 1
+	user.IsActive = true
+	r.db = append(r.db, user)
+	return user.ID, nil
+}
+
+// SetPassword replaces the password hash of a user.
+func (r *UserRepo) SetPassword(ctx context.Context, id int, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.db {
+		if r.db[i].ID == id {
+			r.db[i].Password = hash
+			return nil
+		}
+	}
+	return errors.New("user not found")
+}
+
+func (r *UserRepo) GetUserByID(ctx context.Context, id int) (*models.UserDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.db {
+		if u.ID == id {
+			return &u, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+// GetUserByUsername looks a user up by username, case-insensitively
+func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (*models.UserDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.db {
+		if strings.EqualFold(u.Username, username) {
+			return &u, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
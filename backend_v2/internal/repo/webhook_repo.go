@@ -0,0 +1,171 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"online-judge/internal/models"
+	"sync"
+	"time"
+)
+
+// maxDeliveriesPerWebhook bounds the delivery log kept for each webhook.
+const maxDeliveriesPerWebhook = 200
+
+// WebhookRepo stores registered webhooks and the log of their deliveries.
+type WebhookRepo struct {
+	mu         sync.RWMutex
+	db         []models.WebhookDB
+	deleted    map[int]bool
+	deliveries []models.WebhookDelivery
+}
+
+func NewWebhookRepo() *WebhookRepo {
+	return &WebhookRepo{
+		db:         make([]models.WebhookDB, 0),
+		deleted:    make(map[int]bool),
+		deliveries: make([]models.WebhookDelivery, 0),
+	}
+}
+
+func (r *WebhookRepo) CreateWebhook(ctx context.Context, webhook models.WebhookDB) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook.ID = len(r.db) + 1
+	webhook.Events = append([]string(nil), webhook.Events...)
+	webhook.CreatedAt = time.Now()
+	r.db = append(r.db, webhook)
+	return webhook.ID, nil
+}
+
+// UpdateWebhook replaces the URL, events and active flag of a webhook. The secret is kept
+// unless a new one is given.
+func (r *WebhookRepo) UpdateWebhook(ctx context.Context, webhook models.WebhookDB) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := webhook.ID - 1
+	if i < 0 || i >= len(r.db) || r.deleted[webhook.ID] {
+		return errors.New("webhook not found")
+	}
+	r.db[i].URL = webhook.URL
+	r.db[i].Events = append([]string(nil), webhook.Events...)
+	r.db[i].Active = webhook.Active
+	if webhook.Secret != "" {
+		r.db[i].Secret = webhook.Secret
+	}
+	return nil
+}
+
+func (r *WebhookRepo) DeleteWebhook(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.db) || r.deleted[id] {
+		return errors.New("webhook not found")
+	}
+	r.deleted[id] = true
+	return nil
+}
+
+// GetWebhook returns a webhook including its secret.
+func (r *WebhookRepo) GetWebhook(ctx context.Context, id int) (models.WebhookDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if id < 1 || id > len(r.db) || r.deleted[id] {
+		return models.WebhookDB{}, errors.New("webhook not found")
+	}
+	return r.db[id-1], nil
+}
+
+// ListWebhooks returns every webhook without its secret.
+func (r *WebhookRepo) ListWebhooks(ctx context.Context) ([]models.WebhookDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]models.WebhookDB, 0, len(r.db))
+	for _, w := range r.db {
+		if r.deleted[w.ID] {
+			continue
+		}
+		w.Secret = ""
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, nil
+}
+
+// GetSubscribers returns the active webhooks, with secrets, that want the event.
+func (r *WebhookRepo) GetSubscribers(ctx context.Context, event string) ([]models.WebhookDB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]models.WebhookDB, 0)
+	for _, w := range r.db {
+		if w.Active && !r.deleted[w.ID] && w.Subscribes(event) {
+			webhooks = append(webhooks, w)
+		}
+	}
+	return webhooks, nil
+}
+
+// NewDelivery logs a pending delivery and returns its ID. The oldest deliveries of the
+// webhook are dropped once it has more than maxDeliveriesPerWebhook.
+func (r *WebhookRepo) NewDelivery(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery.ID = len(r.deliveries) + 1
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.Attempts = []models.WebhookAttempt{}
+	delivery.CreatedAt = time.Now()
+	r.deliveries = append(r.deliveries, delivery)
+
+	count := 0
+	for i := len(r.deliveries) - 1; i >= 0; i-- {
+		d := &r.deliveries[i]
+		if d.WebhookID != delivery.WebhookID || d.Payload == nil {
+			continue
+		}
+		count++
+		if count > maxDeliveriesPerWebhook && d.Status != models.WebhookDeliveryPending {
+			// Keep the slot so IDs stay positional, but release the payload.
+			d.Payload = nil
+			d.Attempts = nil
+		}
+	}
+	return delivery.ID, nil
+}
+
+// RecordAttempt appends an attempt to a delivery and sets its status. nextRetry is the
+// time of the next attempt while the delivery is still pending.
+func (r *WebhookRepo) RecordAttempt(ctx context.Context, id int, attempt models.WebhookAttempt, status models.WebhookDeliveryStatus, nextRetry *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.deliveries) {
+		return errors.New("delivery not found")
+	}
+	d := &r.deliveries[id-1]
+	d.Attempts = append(d.Attempts, attempt)
+	d.Status = status
+	d.NextRetryAt = nextRetry
+	return nil
+}
+
+// ListDeliveries returns the logged deliveries of a webhook, newest first.
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, webhookID int) ([]models.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for i := len(r.deliveries) - 1; i >= 0; i-- {
+		d := r.deliveries[i]
+		if d.WebhookID != webhookID || d.Payload == nil {
+			continue
+		}
+		d.Attempts = append([]models.WebhookAttempt(nil), d.Attempts...)
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
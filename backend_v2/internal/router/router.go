@@ -1,55 +1,128 @@
-package router
-
-import (
-	"database/sql"
-	"net/http"
-	authmodule "online-judge/internal/auth_module"
-	"online-judge/internal/handlers"
-	custom_middleware "online-judge/internal/middleware"
-
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
-)
-
-func NewChiRouter(db *sql.DB, auth *authmodule.JWTAuth,
-	handler handlers.Handler,
-) http.Handler {
-	r := chi.NewRouter()
-
-	// Middleware
-	r.Use(middleware.Logger)
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
-
-	// health check
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("healthy"))
-	})
-
-	// Public routes
-	r.Post("/signup", handler.Signup)
-	r.Post("/login", handler.Login)
-
-	// Protected routes
-	r.Route("/api", func(r chi.Router) {
-		r.Use(custom_middleware.JWTAuthMiddleware(auth))
-		// r.Get("/profile", handler.ProfileHandler(db))
-
-		r.Get("/problems", handler.GetProblemList)
-		r.Get("/problems/{problemID}", handler.ViewProblem)
-		r.Post("/problems", handler.CreateProblem)
-		r.Put("/problems", handler.UpdateProblem)
-
-		r.Post("/submit", handler.SubmitCode)
-		r.Get("/submissions/{submissionID}", handler.GetSubmissionResultByID)
-	})
-
-	return r
-}
+package router
+
+import (
+	"database/sql"
+	"net/http"
+	authmodule "online-judge/internal/auth_module"
+	"online-judge/internal/handlers"
+	custom_middleware "online-judge/internal/middleware"
+	"online-judge/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
+)
+
+func NewChiRouter(db *sql.DB, auth *authmodule.JWTAuth,
+	handler handlers.Handler,
+) http.Handler {
+	r := chi.NewRouter()
+
+	// Middleware
+	r.Use(middleware.Logger)
+	r.Use(cors.Handler(cors.Options{
+		AllowedOrigins:   []string{"*"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "Idempotency-Key"},
+		AllowCredentials: true,
+		ExposedHeaders:   []string{"Retry-After", "Idempotent-Replayed"},
+		MaxAge:           300,
+	}))
+
+	// health check
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("healthy"))
+	})
+
+	// Public routes
+	r.Post("/signup", handler.Signup)
+	r.Post("/login", handler.Login)
+
+	// Protected routes. Personal access tokens are limited to the routes their scopes allow.
+	r.Route("/api", func(r chi.Router) {
+		r.Use(custom_middleware.JWTAuthMiddleware(auth, &handler))
+		// r.Get("/profile", handler.ProfileHandler(db))
+
+		// Personal access tokens (sessions only)
+		r.Group(func(r chi.Router) {
+			r.Use(custom_middleware.SessionOnly)
+			r.Get("/tokens", handler.ListTokens)
+			r.Post("/tokens", handler.CreateToken)
+			r.Delete("/tokens/{tokenID}", handler.RevokeToken)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(custom_middleware.RequireScope(models.TokenScopeRead))
+			r.Get("/users/{username}", handler.GetUserProfile)
+			r.Get("/leaderboard", handler.GetLeaderboard)
+
+			r.Get("/problems", handler.GetProblemList)
+			r.Get("/problems/search", handler.SearchProblems)
+			r.Get("/problems/{problemID}", handler.ViewProblem)
+
+			r.Get("/contests", handler.ListContests)
+			r.Get("/contests/{contestID}", handler.GetContest)
+
+			r.Get("/runs/{runID}", handler.GetRunResultByID)
+			r.Get("/submissions", handler.ListSubmissions)
+			r.Get("/submissions/{submissionID}", handler.GetSubmissionResultByID)
+			r.Get("/submissions/{submissionID}/code", handler.GetSubmissionCode)
+			r.Get("/submissions/{submissionID}/tests", handler.GetSubmissionTestResults)
+		})
+
+		// Problem authoring and review
+		r.Group(func(r chi.Router) {
+			r.Use(custom_middleware.RequireScope(models.TokenScopeAdminProblems))
+			r.Post("/problems", handler.CreateProblem)
+			r.Put("/problems", handler.UpdateProblem)
+
+			// Admin only
+			r.Group(func(r chi.Router) {
+				r.Use(custom_middleware.AdminOnly)
+				r.Post("/problems/import", handler.ImportProblem)
+				r.Post("/problems/{problemID}/import", handler.ReimportProblem)
+				r.Get("/problems/{problemID}/export", handler.ExportProblem)
+
+				r.Post("/problems/{problemID}/approve", handler.ApproveProblem)
+				r.Post("/problems/{problemID}/reject", handler.RejectProblem)
+				r.Post("/problems/{problemID}/deactivate", handler.DeactivateProblem)
+				r.Get("/problems/{problemID}/history", handler.GetProblemStatusHistory)
+				r.Get("/problems/{problemID}/validation", handler.GetProblemValidationReport)
+
+				r.Get("/problems/{problemID}/testcases", handler.GetProblemTestCases)
+				r.Post("/problems/{problemID}/testcases", handler.AddProblemTestCases)
+				r.Post("/problems/{problemID}/testcases/upload", handler.UploadProblemTestCases)
+				r.Put("/problems/{problemID}/testcases/order", handler.ReorderProblemTestCases)
+				r.Put("/problems/{problemID}/testcases/{testCaseID}", handler.UpdateProblemTestCase)
+				r.Delete("/problems/{problemID}/testcases/{testCaseID}", handler.DeleteProblemTestCase)
+
+				r.Get("/admin/metrics/queue", handler.GetQueueMetrics)
+
+				r.Post("/submissions/{submissionID}/rejudge", handler.RejudgeSubmission)
+				r.Post("/problems/{problemID}/rejudge", handler.RejudgeProblem)
+				r.Post("/rejudge", handler.RejudgeRange)
+				r.Get("/rejudges/{rejudgeID}", handler.GetRejudge)
+
+				r.Post("/contests", handler.CreateContest)
+				r.Put("/contests/{contestID}", handler.UpdateContest)
+
+				r.Get("/webhooks", handler.ListWebhooks)
+				r.Post("/webhooks", handler.CreateWebhook)
+				r.Put("/webhooks/{webhookID}", handler.UpdateWebhook)
+				r.Delete("/webhooks/{webhookID}", handler.DeleteWebhook)
+				r.Get("/webhooks/{webhookID}/deliveries", handler.ListWebhookDeliveries)
+			})
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(custom_middleware.RequireScope(models.TokenScopeSubmit))
+			r.Post("/contests/{contestID}/register", handler.RegisterForContest)
+			r.Post("/problems/{problemID}/run", handler.RunCode)
+			r.Post("/submit", handler.SubmitCode)
+			r.Get("/ws/submissions", handler.SubmissionUpdates)
+		})
+	})
+
+	return r
+}
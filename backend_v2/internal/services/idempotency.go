@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyKeyTTL is how long a request can be safely retried with the same Idempotency-Key.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// maxIdempotencyClaims bounds how often a claim is retried when the key keeps being
+// released by failing requests in the meantime.
+const maxIdempotencyClaims = 3
+
+// ErrIdempotencyKeyReused is returned when a key is claimed again for a different request.
+var ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+
+func idempotencyKey(scope string, userID int, key string) string {
+	return fmt.Sprintf("idempotency:%s:%d:%s", scope, userID, key)
+}
+
+// idempotencyRecord is stored under a key as "fingerprint:id", where fingerprint hashes the
+// request body and id is 0 while the first request is in flight.
+func idempotencyRecord(fingerprint string, id int) string {
+	return fingerprint + ":" + strconv.Itoa(id)
+}
+
+// ClaimIdempotencyKey reserves an Idempotency-Key of a user for the given scope, e.g.
+// "submit", and request fingerprint. It returns claimed when the caller made the first
+// request with the key and must finish it with CompleteIdempotencyKey or
+// ReleaseIdempotencyKey. Otherwise it returns the ID created by the first request, or 0
+// while that request is still in flight, and ErrIdempotencyKeyReused when the first
+// request had a different fingerprint.
+func (r *RedisService) ClaimIdempotencyKey(ctx context.Context, scope string, userID int, key, fingerprint string) (int, bool, error) {
+	redisKey := idempotencyKey(scope, userID, key)
+	for range maxIdempotencyClaims {
+		claimed, err := r.client.SetNX(ctx, redisKey, idempotencyRecord(fingerprint, 0), IdempotencyKeyTTL).Result()
+		if err != nil || claimed {
+			return 0, claimed, err
+		}
+
+		value, err := r.client.Get(ctx, redisKey).Result()
+		if err == redis.Nil {
+			// The first request failed and released the key in the meantime.
+			continue
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		first, idValue, _ := strings.Cut(value, ":")
+		id, err := strconv.Atoi(idValue)
+		if err != nil {
+			return 0, false, fmt.Errorf("corrupt idempotency record %q: %w", redisKey, err)
+		}
+		if first != fingerprint {
+			return 0, false, ErrIdempotencyKeyReused
+		}
+		return id, false, nil
+	}
+	return 0, false, fmt.Errorf("idempotency key %q is contended, retry later", key)
+}
+
+// CompleteIdempotencyKey stores the ID created by the request that claimed the key,
+// keeping the fingerprint it was claimed with.
+func (r *RedisService) CompleteIdempotencyKey(ctx context.Context, scope string, userID int, key string, id int) error {
+	redisKey := idempotencyKey(scope, userID, key)
+	value, err := r.client.Get(ctx, redisKey).Result()
+	if err != nil {
+		return err
+	}
+	fingerprint, _, _ := strings.Cut(value, ":")
+	return r.client.Set(ctx, redisKey, idempotencyRecord(fingerprint, id), IdempotencyKeyTTL).Err()
+}
+
+// ReleaseIdempotencyKey forgets a claimed key whose request failed, so it can be retried.
+func (r *RedisService) ReleaseIdempotencyKey(ctx context.Context, scope string, userID int, key string) error {
+	return r.client.Del(ctx, idempotencyKey(scope, userID, key)).Err()
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// idempotencyStep is one call on the key "k" of user 2 in the "submit" scope, unless the
+// step names another user.
+type idempotencyStep struct {
+	action      string // claim, complete or release
+	userID      int
+	fingerprint string
+	id          int
+
+	wantID      int
+	wantClaimed bool
+	wantErr     error
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []idempotencyStep
+	}{
+		{
+			name:  "first claim",
+			steps: []idempotencyStep{{action: "claim", fingerprint: "a", wantClaimed: true}},
+		},
+		{
+			name: "in flight",
+			steps: []idempotencyStep{
+				{action: "claim", fingerprint: "a", wantClaimed: true},
+				{action: "claim", fingerprint: "a"},
+			},
+		},
+		{
+			name: "replayed",
+			steps: []idempotencyStep{
+				{action: "claim", fingerprint: "a", wantClaimed: true},
+				{action: "complete", id: 7},
+				{action: "claim", fingerprint: "a", wantID: 7},
+			},
+		},
+		{
+			name: "different body",
+			steps: []idempotencyStep{
+				{action: "claim", fingerprint: "a", wantClaimed: true},
+				{action: "complete", id: 7},
+				{action: "claim", fingerprint: "b", wantErr: ErrIdempotencyKeyReused},
+			},
+		},
+		{
+			name: "released after a failure",
+			steps: []idempotencyStep{
+				{action: "claim", fingerprint: "a", wantClaimed: true},
+				{action: "release"},
+				{action: "claim", fingerprint: "b", wantClaimed: true},
+			},
+		},
+		{
+			name: "keys are per user",
+			steps: []idempotencyStep{
+				{action: "claim", fingerprint: "a", wantClaimed: true},
+				{action: "claim", userID: 3, fingerprint: "b", wantClaimed: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestRedis(t)
+			ctx := context.Background()
+			for i, step := range tt.steps {
+				userID := step.userID
+				if userID == 0 {
+					userID = 2
+				}
+				var id int
+				var claimed bool
+				var err error
+				switch step.action {
+				case "claim":
+					id, claimed, err = r.ClaimIdempotencyKey(ctx, "submit", userID, "k", step.fingerprint)
+				case "complete":
+					err = r.CompleteIdempotencyKey(ctx, "submit", userID, "k", step.id)
+				case "release":
+					err = r.ReleaseIdempotencyKey(ctx, "submit", userID, "k")
+				}
+				if id != step.wantID || claimed != step.wantClaimed || !errors.Is(err, step.wantErr) {
+					t.Fatalf("step %d (%s) = %d, %v, %v, want %d, %v, %v",
+						i+1, step.action, id, claimed, err, step.wantID, step.wantClaimed, step.wantErr)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// leaderboardKey is a sorted set of user IDs scored by the number of problems they solved.
+const leaderboardKey = "leaderboard:solved"
+
+// UpdateLeaderboard sets the number of problems a user has solved.
+func (r *RedisService) UpdateLeaderboard(ctx context.Context, userID, solved int) error {
+	return r.client.ZAdd(ctx, leaderboardKey, redis.Z{Score: float64(solved), Member: userID}).Err()
+}
+
+// RebuildLeaderboard replaces the leaderboard with the given solved counts per user ID.
+// The new board is built aside and swapped in so readers never see it half filled.
+func (r *RedisService) RebuildLeaderboard(ctx context.Context, solved map[int]int) error {
+	if len(solved) == 0 {
+		return r.client.Del(ctx, leaderboardKey).Err()
+	}
+
+	members := make([]redis.Z, 0, len(solved))
+	for userID, count := range solved {
+		members = append(members, redis.Z{Score: float64(count), Member: userID})
+	}
+
+	tmp := leaderboardKey + ":rebuild"
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, tmp)
+	pipe.ZAdd(ctx, tmp, members...)
+	pipe.Rename(ctx, tmp, leaderboardKey)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// LeaderboardPosition is a user on the leaderboard. Users with the same score share a
+// rank, which is one more than the number of users with a higher score.
+type LeaderboardPosition struct {
+	UserID int
+	Rank   int
+}
+
+// LeaderboardPage returns up to count users from the given 0-based offset, best first,
+// together with the number of users on the board.
+func (r *RedisService) LeaderboardPage(ctx context.Context, offset, count int) ([]LeaderboardPosition, int, error) {
+	pipe := r.client.Pipeline()
+	total := pipe.ZCard(ctx, leaderboardKey)
+	members := pipe.ZRevRangeWithScores(ctx, leaderboardKey, int64(offset), int64(offset+count-1))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	positions := make([]LeaderboardPosition, 0, len(members.Val()))
+	rank := 0
+	for i, m := range members.Val() {
+		switch {
+		case i == 0:
+			// Ties of the first user may start on an earlier page.
+			above, err := r.countAbove(ctx, m.Score)
+			if err != nil {
+				return nil, 0, err
+			}
+			rank = above + 1
+		case m.Score != members.Val()[i-1].Score:
+			rank = offset + i + 1
+		}
+		id, err := strconv.Atoi(fmt.Sprint(m.Member))
+		if err != nil {
+			continue
+		}
+		positions = append(positions, LeaderboardPosition{UserID: id, Rank: rank})
+	}
+	return positions, int(total.Val()), nil
+}
+
+// LeaderboardRank returns the 1-based rank of a user, or 0 when the user is not ranked.
+func (r *RedisService) LeaderboardRank(ctx context.Context, userID int) (int, error) {
+	score, err := r.client.ZScore(ctx, leaderboardKey, strconv.Itoa(userID)).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	above, err := r.countAbove(ctx, score)
+	if err != nil {
+		return 0, err
+	}
+	return above + 1, nil
+}
+
+// countAbove counts the users with a higher score.
+func (r *RedisService) countAbove(ctx context.Context, score float64) (int, error) {
+	n, err := r.client.ZCount(ctx, leaderboardKey, "("+strconv.FormatFloat(score, 'f', -1, 64), "+inf").Result()
+	return int(n), err
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+
+	"online-judge/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Priority lanes of the execution queue, highest priority first. Each lane is a Redis sorted
+// set named "<language>:<lane>"; the execution service consumes them in weighted order so bulk
+// traffic cannot starve interactive runs. Keep in sync with the worker's lane weights.
+const (
+	LaneRun        = "run"
+	LaneSubmit     = "submit"
+	LaneValidation = "validation"
+	LaneRejudge    = "rejudge"
+)
+
+var Lanes = []string{LaneRun, LaneSubmit, LaneValidation, LaneRejudge}
+
+// LaneFor returns the lane jobs of the given execution type are queued on.
+func LaneFor(executionType string) string {
+	switch executionType {
+	case models.ExecutionTypeRun, models.ExecutionTypeRunReference:
+		return LaneRun
+	case models.ExecutionTypeValidation, models.ExecutionTypeGeneration:
+		return LaneValidation
+	case models.ExecutionTypeRejudge:
+		return LaneRejudge
+	default:
+		return LaneSubmit
+	}
+}
+
+func laneKey(language, lane string) string {
+	return language + ":" + lane
+}
+
+// fairEnqueueScript adds a job to the lane in KEYS[1] using start-time fair queueing: a job
+// is scored one past the later of the lane's head and the user's previous job, so a user
+// with many queued jobs is interleaved round-robin with everybody else instead of blocking
+// them. KEYS[2] holds the score of each user's latest job. The worker pops the lowest score.
+// ARGV: user ID, job. Returns the number of jobs up to and including this one.
+var fairEnqueueScript = redis.NewScript(`
+local head = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local score = 1
+if head[2] then
+	local last = tonumber(redis.call('HGET', KEYS[2], ARGV[1])) or 0
+	score = math.max(tonumber(head[2]), last) + 1
+else
+	redis.call('DEL', KEYS[2])
+end
+redis.call('HSET', KEYS[2], ARGV[1], score)
+redis.call('ZADD', KEYS[1], score, ARGV[2])
+return redis.call('ZCOUNT', KEYS[1], '-inf', score)
+`)
+
+// enqueue adds a job to a lane, interleaving it fairly with the jobs of other users, and
+// returns its 1-based position in the lane.
+func (r *RedisService) enqueue(ctx context.Context, language, lane string, userID int, job []byte) (int, error) {
+	key := laneKey(language, lane)
+	position, err := fairEnqueueScript.Run(ctx, r.client, []string{key, key + ":users"}, userID, job).Int()
+	if err != nil {
+		return 0, err
+	}
+	return position, nil
+}
+
+// QueueDepths returns the number of jobs waiting on each lane of a language.
+func (r *RedisService) QueueDepths(ctx context.Context, language string) (map[string]int64, error) {
+	pipe := r.client.Pipeline()
+	lengths := make(map[string]*redis.IntCmd, len(Lanes))
+	for _, lane := range Lanes {
+		lengths[lane] = pipe.ZCard(ctx, laneKey(language, lane))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	depths := make(map[string]int64, len(Lanes))
+	for lane, length := range lengths {
+		depths[lane] = length.Val()
+	}
+	return depths, nil
+}
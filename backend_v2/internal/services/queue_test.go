@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"online-judge/internal/models"
+)
+
+func TestLaneFor(t *testing.T) {
+	tests := []struct {
+		executionType string
+		want          string
+	}{
+		{models.ExecutionTypeRun, LaneRun},
+		{models.ExecutionTypeRunReference, LaneRun},
+		{models.ExecutionTypeSubmission, LaneSubmit},
+		{models.ExecutionTypeValidation, LaneValidation},
+		{models.ExecutionTypeGeneration, LaneValidation},
+		{models.ExecutionTypeRejudge, LaneRejudge},
+		{"", LaneSubmit},
+	}
+	for _, tt := range tests {
+		if got := LaneFor(tt.executionType); got != tt.want {
+			t.Errorf("LaneFor(%q) = %q, want %q", tt.executionType, got, tt.want)
+		}
+	}
+}
+
+func TestFairEnqueue(t *testing.T) {
+	// job is a queued job named after its user and its number for that user, e.g. "a2".
+	type job struct {
+		userID int
+		name   string
+	}
+	tests := []struct {
+		name      string
+		jobs      []job
+		positions []int
+		order     []string
+	}{
+		{
+			name:      "one user",
+			jobs:      []job{{1, "a1"}, {1, "a2"}, {1, "a3"}},
+			positions: []int{1, 2, 3},
+			order:     []string{"a1", "a2", "a3"},
+		},
+		{
+			name:      "late user interleaved",
+			jobs:      []job{{1, "a1"}, {1, "a2"}, {1, "a3"}, {2, "b1"}, {2, "b2"}},
+			positions: []int{1, 2, 3, 3, 5},
+			order:     []string{"a1", "a2", "b1", "a3", "b2"},
+		},
+		{
+			name:      "round robin",
+			jobs:      []job{{1, "a1"}, {1, "a2"}, {2, "b1"}, {2, "b2"}, {3, "c1"}},
+			positions: []int{1, 2, 3, 4, 4},
+			order:     []string{"a1", "a2", "b1", "c1", "b2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestRedis(t)
+			ctx := context.Background()
+			for i, j := range tt.jobs {
+				position, err := r.enqueue(ctx, "python", LaneSubmit, j.userID, []byte(j.name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if position != tt.positions[i] {
+					t.Errorf("%s enqueued at %d, want %d", j.name, position, tt.positions[i])
+				}
+			}
+
+			order, err := r.client.ZRange(ctx, laneKey("python", LaneSubmit), 0, -1).Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(order, tt.order) {
+				t.Errorf("queue = %v, want %v", order, tt.order)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// QueueLimits bound how much work a single user can put on the execution queue.
+type QueueLimits struct {
+	SubmitRatePerMinute int // tokens added to a user's submission bucket per minute
+	SubmitBurst         int // size of a user's submission bucket
+	MaxPendingPerUser   int // submissions a user may have waiting for a verdict
+
+	MaxQueueDepth int           // jobs waiting ahead of a submission above which it is rejected
+	MaxQueueWait  time.Duration // estimated wait above which a submission is rejected
+}
+
+// tokenBucketScript takes one token from the bucket in KEYS[1] if it has one. The bucket is
+// refilled lazily from the time elapsed since it was last touched.
+// ARGV: rate (tokens per ms), burst, now (ms). Returns {allowed, ms until a token is available}.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate))
+
+if allowed == 1 then
+	return {1, 0}
+end
+return {0, math.ceil((1 - tokens) / rate)}
+`)
+
+// AllowSubmission takes a token from the user's submission bucket. When the bucket is
+// empty it returns false and how long to wait for the next token. Buckets live in Redis
+// so the quota holds across API replicas.
+func (r *RedisService) AllowSubmission(ctx context.Context, userID int, limits QueueLimits) (bool, time.Duration, error) {
+	rate := float64(limits.SubmitRatePerMinute) / float64(time.Minute.Milliseconds())
+	key := fmt.Sprintf("ratelimit:submit:%d", userID)
+
+	res, err := tokenBucketScript.Run(ctx, r.client, []string{key}, rate, limits.SubmitBurst, time.Now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
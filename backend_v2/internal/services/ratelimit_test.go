@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAllowSubmission(t *testing.T) {
+	limits := QueueLimits{SubmitRatePerMinute: 6, SubmitBurst: 3}
+	tests := []struct {
+		name    string
+		takes   int // submissions user 2 made before
+		userID  int
+		allowed bool
+	}{
+		{name: "first", userID: 2, allowed: true},
+		{name: "within the burst", takes: 2, userID: 2, allowed: true},
+		{name: "burst used up", takes: 3, userID: 2},
+		{name: "buckets are per user", takes: 3, userID: 3, allowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestRedis(t)
+			ctx := context.Background()
+			for i := 0; i < tt.takes; i++ {
+				if ok, _, err := r.AllowSubmission(ctx, 2, limits); err != nil || !ok {
+					t.Fatalf("submission %d rejected: %v", i+1, err)
+				}
+			}
+
+			allowed, retryAfter, err := r.AllowSubmission(ctx, tt.userID, limits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if allowed != tt.allowed {
+				t.Errorf("allowed = %v, want %v", allowed, tt.allowed)
+			}
+			// At 6 tokens a minute the next one is at most 10 s away.
+			if allowed && retryAfter != 0 || !allowed && (retryAfter <= 0 || retryAfter > 10*time.Second) {
+				t.Errorf("retry after %v", retryAfter)
+			}
+		})
+	}
+}
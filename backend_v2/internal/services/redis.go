@@ -29,6 +29,31 @@ func (r *RedisService) Close() {
 func (r *RedisService) StartResultWorker(
 	ctx context.Context, handleResultFunc func(*models.ExecuteCodeResponse), wg *sync.WaitGroup,
 ) {
+	r.consume(ctx, "results_queue", func(data []byte) {
+		var result models.ExecuteCodeResponse
+		if err := json.Unmarshal(data, &result); err != nil {
+			// log.Printf("Invalid result JSON: %v", err)
+			return
+		}
+		handleResultFunc(&result)
+	}, wg)
+}
+
+// StartStatusWorker consumes the lifecycle events reported by the execution service.
+func (r *RedisService) StartStatusWorker(
+	ctx context.Context, handleEventFunc func(*models.ExecutionEvent), wg *sync.WaitGroup,
+) {
+	r.consume(ctx, "status_queue", func(data []byte) {
+		var event models.ExecutionEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return
+		}
+		handleEventFunc(&event)
+	}, wg)
+}
+
+// consume pops messages from a Redis list until ctx is cancelled.
+func (r *RedisService) consume(ctx context.Context, key string, handle func([]byte), wg *sync.WaitGroup) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -39,7 +64,7 @@ func (r *RedisService) StartResultWorker(
 				// log.Println("🛑 Result worker shutting down...")
 				return
 			default:
-				res, err := r.client.BLPop(ctx, 5*time.Second, "results_queue").Result()
+				res, err := r.client.BLPop(ctx, 5*time.Second, key).Result()
 				if err != nil {
 					if err == redis.Nil {
 						continue // no result yet
@@ -53,22 +78,24 @@ func (r *RedisService) StartResultWorker(
 					continue
 				}
 
-				var result models.ExecuteCodeResponse
-				if err := json.Unmarshal([]byte(res[1]), &result); err != nil {
-					// log.Printf("Invalid result JSON: %v", err)
-					continue
-				}
-
-				handleResultFunc(&result)
+				handle([]byte(res[1]))
 			}
 		}
 	}()
 }
 
+// ExecuteCode enqueues a job for the execution service on the priority lane of its
+// execution type, interleaved round-robin with the jobs of other users.
 func (r *RedisService) ExecuteCode(ctx context.Context, language string, payload models.ExecuteCodePayload) error {
+	_, err := r.ExecuteCodeWithPosition(ctx, language, payload)
+	return err
+}
+
+// ExecuteCodeWithPosition is ExecuteCode that also returns the job's 1-based position in its lane.
+func (r *RedisService) ExecuteCodeWithPosition(ctx context.Context, language string, payload models.ExecuteCodePayload) (int, error) {
 	data, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	return r.client.RPush(ctx, language, data).Err()
+	return r.enqueue(ctx, language, LaneFor(payload.ExecutionType), payload.UserID, data)
 }
@@ -0,0 +1,17 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestRedis returns a RedisService backed by an in-memory Redis that is shut down with
+// the test.
+func newTestRedis(t *testing.T) (*RedisService, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	r := NewRedisService(mr.Addr())
+	t.Cleanup(r.Close)
+	return r, mr
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"online-judge/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ResultCacheTTL bounds how long a cached verdict is served.
+const ResultCacheTTL = 24 * time.Hour
+
+func resultCacheKey(hash string) string {
+	return "resultcache:" + hash
+}
+
+// GetCachedResult returns the cached execution result for a job hash, if any.
+func (r *RedisService) GetCachedResult(ctx context.Context, hash string) (*models.ExecuteCodeResponse, bool, error) {
+	data, err := r.client.Get(ctx, resultCacheKey(hash)).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var result models.ExecuteCodeResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, false, err
+	}
+	return &result, true, nil
+}
+
+// CacheResult stores the execution result of a job under its hash.
+func (r *RedisService) CacheResult(ctx context.Context, hash string, result *models.ExecuteCodeResponse) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, resultCacheKey(hash), data, ResultCacheTTL).Err()
+}
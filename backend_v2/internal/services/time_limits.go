@@ -0,0 +1,49 @@
+package services
+
+import (
+	"math"
+	"strings"
+)
+
+// CalibrationLimitMS is the time limit validation runs under when time limits are set
+// automatically, so that the reference solution is measured instead of cut off.
+const CalibrationLimitMS = 10000
+
+// Calibrated limits are rounded up to a multiple of timeLimitStepMS.
+const timeLimitStepMS = 100
+
+// TimeLimits derive the time limit of each language from the base limit of a problem, and
+// calibrate base limits from the reference solution.
+type TimeLimits struct {
+	Factor      float64            // limit as a multiple of the reference solution's worst time
+	Auto        bool               // set calibrated limits instead of only suggesting them
+	Multipliers map[string]float64 // lower-case language name -> multiplier, 1 when absent
+}
+
+// Multiplier returns the time multiplier of a language.
+func (t TimeLimits) Multiplier(language string) float64 {
+	if m, ok := t.Multipliers[strings.ToLower(language)]; ok && m > 0 {
+		return m
+	}
+	return 1
+}
+
+// ForLanguage returns the time limit of a language for a problem with the given base limit.
+func (t TimeLimits) ForLanguage(baseMS int, language string) int {
+	return int(math.Ceil(float64(baseMS) * t.Multiplier(language)))
+}
+
+// Suggest returns the base time limit for a problem whose reference solution, written in
+// language, took worstMS on its slowest test.
+func (t TimeLimits) Suggest(worstMS int, language string) int {
+	factor := t.Factor
+	if factor <= 0 {
+		factor = 1
+	}
+	base := float64(worstMS) * factor / t.Multiplier(language)
+	steps := int(math.Ceil(base / timeLimitStepMS))
+	if steps < 1 {
+		steps = 1
+	}
+	return steps * timeLimitStepMS
+}
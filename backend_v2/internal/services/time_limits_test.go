@@ -0,0 +1,48 @@
+package services
+
+import "testing"
+
+func TestTimeLimitsForLanguage(t *testing.T) {
+	limits := TimeLimits{Multipliers: map[string]float64{"go": 1, "python": 3, "javascript": 1.5}}
+	tests := []struct {
+		baseMS   int
+		language string
+		want     int
+	}{
+		{1000, "Go", 1000},
+		{1000, "Python", 3000},
+		{1000, "python", 3000},
+		{333, "JavaScript", 500},
+		{1000, "Rust", 1000},
+		{1000, "", 1000},
+	}
+	for _, tt := range tests {
+		if got := limits.ForLanguage(tt.baseMS, tt.language); got != tt.want {
+			t.Errorf("ForLanguage(%d, %q) = %d, want %d", tt.baseMS, tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestTimeLimitsSuggest(t *testing.T) {
+	tests := []struct {
+		name     string
+		factor   float64
+		worstMS  int
+		language string
+		want     int
+	}{
+		{name: "rounded up", factor: 2, worstMS: 120, language: "Go", want: 300},
+		{name: "exact step", factor: 2, worstMS: 150, language: "Go", want: 300},
+		{name: "divided by the multiplier", factor: 2, worstMS: 900, language: "Python", want: 600},
+		{name: "at least one step", factor: 2, worstMS: 0, language: "Go", want: 100},
+		{name: "no factor", factor: 0, worstMS: 250, language: "Go", want: 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits := TimeLimits{Factor: tt.factor, Multipliers: map[string]float64{"go": 1, "python": 3}}
+			if got := limits.Suggest(tt.worstMS, tt.language); got != tt.want {
+				t.Errorf("Suggest(%d, %q) = %d, want %d", tt.worstMS, tt.language, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"online-judge/internal/models"
+)
+
+// verdictChannel carries judged submissions to every API replica.
+const verdictChannel = "verdicts"
+
+// PublishVerdict announces a judged submission to all API replicas.
+func (r *RedisService) PublishVerdict(ctx context.Context, event models.VerdictEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, verdictChannel, data).Err()
+}
+
+// StartVerdictSubscriber delivers the verdicts published by any replica until ctx is cancelled.
+func (r *RedisService) StartVerdictSubscriber(
+	ctx context.Context, handleVerdictFunc func(*models.VerdictEvent), wg *sync.WaitGroup,
+) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		pubsub := r.client.Subscribe(ctx, verdictChannel)
+		defer pubsub.Close()
+
+		// The channel is reconnected by go-redis and closed when pubsub is.
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event models.VerdictEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					log.Printf("Invalid verdict JSON: %v", err)
+					continue
+				}
+				handleVerdictFunc(&event)
+			}
+		}
+	}()
+}
@@ -0,0 +1,269 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"online-judge/internal/models"
+	"online-judge/internal/repo"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	maxAttempts  = 6
+	baseBackoff  = 5 * time.Second
+	maxBackoff   = 10 * time.Minute
+	queueSize    = 1024
+	workerCount  = 4
+	requestLimit = 10 * time.Second
+)
+
+type job struct {
+	deliveryID int
+	webhookID  int
+	eventID    string
+	event      string
+	body       []byte
+	attempt    int
+}
+
+// Dispatcher posts events to the webhooks subscribed to them. Each delivery is retried
+// with exponential backoff until it gets a 2xx response or runs out of attempts.
+type Dispatcher struct {
+	repo   *repo.WebhookRepo
+	client *http.Client
+	jobs   chan job
+	ctx    context.Context
+}
+
+func NewDispatcher(webhookRepo *repo.WebhookRepo) *Dispatcher {
+	return &Dispatcher{
+		repo:   webhookRepo,
+		client: newClient(),
+		jobs:   make(chan job, queueSize),
+		ctx:    context.Background(),
+	}
+}
+
+// Start runs the delivery workers until ctx is cancelled. Retries still waiting at that
+// point stay pending in the delivery log.
+func (d *Dispatcher) Start(ctx context.Context, wg *sync.WaitGroup) {
+	d.ctx = ctx
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j := <-d.jobs:
+					d.deliver(ctx, j)
+				}
+			}
+		}()
+	}
+}
+
+// Emit queues the event for every active webhook subscribed to it.
+func (d *Dispatcher) Emit(ctx context.Context, event string, data interface{}) {
+	webhooks, err := d.repo.GetSubscribers(ctx, event)
+	if err != nil || len(webhooks) == 0 {
+		return
+	}
+
+	payload := models.WebhookEvent{
+		ID:        newID(),
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Println("Failed to encode webhook event: ", err)
+		return
+	}
+
+	for _, w := range webhooks {
+		id, err := d.repo.NewDelivery(ctx, models.WebhookDelivery{
+			WebhookID: w.ID,
+			EventID:   payload.ID,
+			Event:     event,
+			Payload:   body,
+		})
+		if err != nil {
+			log.Println("Failed to log webhook delivery: ", err)
+			continue
+		}
+		d.enqueue(job{deliveryID: id, webhookID: w.ID, eventID: payload.ID, event: event, body: body, attempt: 1})
+	}
+}
+
+func (d *Dispatcher) enqueue(j job) {
+	select {
+	case d.jobs <- j:
+	default:
+		now := time.Now()
+		_ = d.repo.RecordAttempt(d.ctx, j.deliveryID, models.WebhookAttempt{Error: "delivery queue is full", At: now}, models.WebhookDeliveryFailed, nil)
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, j job) {
+	// The webhook is looked up on every attempt so edits and deletions take effect.
+	webhook, err := d.repo.GetWebhook(ctx, j.webhookID)
+	if err != nil || !webhook.Active {
+		_ = d.repo.RecordAttempt(ctx, j.deliveryID, models.WebhookAttempt{Error: "webhook removed or disabled", At: time.Now()}, models.WebhookDeliveryFailed, nil)
+		return
+	}
+
+	attempt, retryable := d.post(ctx, webhook, j)
+	if attempt.Error == "" {
+		_ = d.repo.RecordAttempt(ctx, j.deliveryID, attempt, models.WebhookDeliverySucceeded, nil)
+		return
+	}
+	if !retryable || j.attempt >= maxAttempts {
+		_ = d.repo.RecordAttempt(ctx, j.deliveryID, attempt, models.WebhookDeliveryFailed, nil)
+		return
+	}
+
+	delay := Backoff(j.attempt)
+	next := time.Now().Add(delay)
+	_ = d.repo.RecordAttempt(ctx, j.deliveryID, attempt, models.WebhookDeliveryPending, &next)
+
+	j.attempt++
+	time.AfterFunc(delay, func() {
+		if ctx.Err() == nil {
+			d.enqueue(j)
+		}
+	})
+}
+
+// post sends one attempt. Network errors, 5xx, 408 and 429 responses are retryable.
+func (d *Dispatcher) post(ctx context.Context, webhook models.WebhookDB, j job) (models.WebhookAttempt, bool) {
+	start := time.Now()
+	attempt := models.WebhookAttempt{At: start}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(j.body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, false
+	}
+	timestamp := strconv.FormatInt(start.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "online-judge-webhooks")
+	req.Header.Set(EventHeader, j.event)
+	req.Header.Set(DeliveryHeader, j.eventID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, j.body))
+
+	resp, err := d.client.Do(req)
+	attempt.DurationMS = int(time.Since(start).Milliseconds())
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, true
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return attempt, false
+	}
+	attempt.Error = resp.Status
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return attempt, retryable
+}
+
+// newClient returns the client deliveries are posted with. It connects only to public
+// addresses, checked after DNS resolution so a hostname cannot point it at the judge's
+// own network, and does not follow redirects: a 3xx response is a failed attempt.
+func newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: requestLimit,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if BlockedAddr(addrPort.Addr()) {
+				return fmt.Errorf("webhook address %s is not public", addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: requestLimit,
+		Transport: &http.Transport{
+			// No proxy: the address check must apply to the webhook host itself.
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: requestLimit,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// BlockedAddr reports whether webhooks may not be delivered to the address: loopback,
+// private, link-local, multicast and unspecified addresses.
+func BlockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() ||
+		addr.IsUnspecified()
+}
+
+// Sign returns the signature header value: "sha256=" followed by the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook secret. Receivers recompute it to verify
+// the payload and reject stale timestamps to prevent replays.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the wait after the given failed attempt: 5s, 10s, 20s... capped at
+// maxBackoff.
+func Backoff(attempt int) time.Duration {
+	delay := baseBackoff << uint(attempt-1)
+	if delay <= 0 || delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
+
+// NewSecret returns a random signing secret.
+func NewSecret() string {
+	return "whsec_" + newID()
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,81 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{5, 80 * time.Second},
+		{7, 320 * time.Second},
+		{8, maxBackoff},
+		{100, maxBackoff},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		secret, timestamp, body string
+		want                    string
+	}{
+		{"whsec_test", "1700000000", `{"id":"1"}`, "sha256=11bf4466ea17c3df3fd743af0b435368e16b7a05eb8eced85e8c4670767bdec5"},
+		{"", "0", "", "sha256=b849d5a581847b281957065739df36df2463d1977ea8d6e1e4e6cf33fadc68c3"},
+	}
+	for _, tt := range tests {
+		got := Sign(tt.secret, tt.timestamp, []byte(tt.body))
+		if got != tt.want {
+			t.Errorf("Sign(%q, %q, %q) = %q, want %q", tt.secret, tt.timestamp, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestBlockedAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"0.0.0.0", true},
+		{"224.0.0.1", true},
+		{"::ffff:127.0.0.1", true},
+		{"93.184.216.34", false},
+		{"2606:4700::1111", false},
+	}
+	for _, tt := range tests {
+		if got := BlockedAddr(netip.MustParseAddr(tt.addr)); got != tt.blocked {
+			t.Errorf("BlockedAddr(%s) = %v, want %v", tt.addr, got, tt.blocked)
+		}
+	}
+}
+
+func TestClientRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := newClient().Post(server.URL, "application/json", nil)
+	if err == nil || !strings.Contains(err.Error(), "not public") {
+		t.Fatalf("err = %v, want the address to be refused", err)
+	}
+}
@@ -33,7 +33,50 @@ func getMemoryUsage(pid int) int {
 	return -1
 }
 
-func ExecutePython(payload ExecuteCodePayload) (response ExecuteCodeResponse) {
+// clockTicksPerSecond is the unit of the CPU times in /proc/<pid>/stat (USER_HZ), which is
+// 100 on every Linux architecture the worker runs on.
+const clockTicksPerSecond = 100
+
+// getCPUTime returns the user and system CPU time a process and its waited-for children
+// have used so far, or -1 when it cannot be read.
+func getCPUTime(pid int) time.Duration {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return -1
+	}
+	// The command name may contain spaces; the numeric fields follow its closing ')'.
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return -1
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 15 {
+		return -1
+	}
+	var ticks int64
+	// utime, stime, cutime and cstime are fields 14 to 17; fields[0] is field 3.
+	for _, f := range fields[11:15] {
+		n, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return -1
+		}
+		ticks += n
+	}
+	return time.Duration(ticks) * time.Second / clockTicksPerSecond
+}
+
+// wallTimeFactor bounds the wall time of a test, as a multiple of its CPU time limit, so
+// that programs which sleep or block on input still time out.
+const wallTimeFactor = 3
+
+// ExecutePython runs a solution on every test. Time limits are enforced on CPU time.
+func ExecutePython(payload ExecuteCodePayload) ExecuteCodeResponse {
+	return executePython(payload, false)
+}
+
+// executePython is ExecutePython, optionally skipping the remaining tests once one of them
+// has timed out.
+func executePython(payload ExecuteCodePayload, stopAfterTLE bool) (response ExecuteCodeResponse) {
 	os.MkdirAll("./out", os.ModePerm)
 	sourcePath := filepath.Join("out", "test.py")
 
@@ -44,13 +87,15 @@ func ExecutePython(payload ExecuteCodePayload) (response ExecuteCodeResponse) {
 			ID:            payload.ID,
 			Status:        "Error writing source file",
 			ExecutionType: payload.ExecutionType,
+			SystemError:   err.Error(),
 		}
 	}
 
 	var results []TestCaseResult
 	finalStatus := "Accepted"
 	for _, tc := range payload.TestCases {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(payload.RuntimeLimitMS)*time.Millisecond)
+		cpuLimit := time.Duration(payload.RuntimeLimitMS) * time.Millisecond
+		ctx, cancel := context.WithTimeout(context.Background(), wallTimeFactor*cpuLimit)
 		defer cancel()
 
 		cmd := exec.CommandContext(ctx, "python3", sourcePath)
@@ -82,6 +127,7 @@ func ExecutePython(payload ExecuteCodePayload) (response ExecuteCodeResponse) {
 		go func() { done <- cmd.Wait() }()
 
 		var status string
+		var cpuTime time.Duration
 		ticker := time.NewTicker(10 * time.Millisecond)
 		defer ticker.Stop()
 
@@ -98,6 +144,14 @@ func ExecutePython(payload ExecuteCodePayload) (response ExecuteCodeResponse) {
 					status = "MLE"
 					break loop
 				}
+				if t := getCPUTime(pid); t > cpuTime {
+					cpuTime = t
+				}
+				if cpuTime > cpuLimit {
+					_ = cmd.Process.Kill()
+					status = "TLE"
+					break loop
+				}
 			case <-ctx.Done():
 				_ = cmd.Process.Kill()
 				status = "TLE"
@@ -108,6 +162,11 @@ func ExecutePython(payload ExecuteCodePayload) (response ExecuteCodeResponse) {
 				} else {
 					status = "Accepted"
 				}
+				cpuTime = cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime()
+				// The process may have gone over the limit since it was last sampled.
+				if cpuTime > cpuLimit {
+					status = "TLE"
+				}
 				break loop
 			}
 		}
@@ -116,8 +175,22 @@ func ExecutePython(payload ExecuteCodePayload) (response ExecuteCodeResponse) {
 		output := strings.TrimSpace(outBuf.String())
 		expected := strings.TrimSpace(tc.ExpectedOutput)
 
-		if status == "Accepted" && output != expected {
-			status = "Wrong Answer"
+		if status == "Accepted" {
+			if payload.CheckerCode != "" {
+				status, err = runChecker(payload.CheckerCode, tc.Input, output, expected)
+				if err != nil {
+					// A broken checker is the problem's fault, not the submission's.
+					log.Println("Checker failed:", err)
+					return ExecuteCodeResponse{
+						ID:            payload.ID,
+						Status:        "System Error",
+						ExecutionType: payload.ExecutionType,
+						SystemError:   "checker failed: " + err.Error(),
+					}
+				}
+			} else if output != expected {
+				status = "Wrong Answer"
+			}
 		}
 
 		if status != "Accepted" {
@@ -130,9 +203,13 @@ func ExecutePython(payload ExecuteCodePayload) (response ExecuteCodeResponse) {
 			Output:         output,
 			ExpectedOutput: expected,
 			RuntimeMS:      int(end.Milliseconds()),
+			CPUTimeMS:      int(cpuTime.Milliseconds()),
 			MemoryKB:       peakMem,
 			Status:         status,
 		})
+		if stopAfterTLE && status == "TLE" {
+			break
+		}
 	}
 
 	response = ExecuteCodeResponse{
@@ -143,3 +220,46 @@ func ExecutePython(payload ExecuteCodePayload) (response ExecuteCodeResponse) {
 	}
 	return response
 }
+
+const checkerTimeout = 10 * time.Second
+
+// runChecker judges an output with a testlib-style checker, run as
+// "checker input output answer": exit code 0 accepts and 1 is a wrong answer. Any other
+// outcome means the checker itself failed and is returned as an error.
+//
+// The checker and its files are written after the submission has exited, to a fresh
+// private directory outside out/, so the submission cannot tamper with them.
+func runChecker(checkerCode, input, output, answer string) (string, error) {
+	dir, err := os.MkdirTemp("", "checker-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	checkerPath := filepath.Join(dir, "checker.py")
+	if err := os.WriteFile(checkerPath, []byte(checkerCode), 0600); err != nil {
+		return "", err
+	}
+	files := make([]string, 0, 3)
+	for i, content := range []string{input, output, answer} {
+		path := filepath.Join(dir, fmt.Sprintf("checker_%d.txt", i))
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			return "", err
+		}
+		files = append(files, path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkerTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "python3", append([]string{checkerPath}, files...)...)
+	cmd.Dir = dir
+	err = cmd.Run()
+	if err == nil {
+		return "Accepted", nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return "Wrong Answer", nil
+	}
+	return "", err
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const generatorTimeout = 10 * time.Second
+
+// GenerateTests materialises the tests of a problem: it runs each test script line
+// "generator arg..." to produce an input, checks the inputs written by hand and the
+// generated ones with the validator, and runs the reference solution to obtain the
+// expected outputs of generated tests. Every result is "Accepted" unless a step failed,
+// in which case the response stops at the failing test.
+func GenerateTests(payload ExecuteCodePayload) ExecuteCodeResponse {
+	response := ExecuteCodeResponse{
+		ID:            payload.ID,
+		Status:        "Accepted",
+		ExecutionType: payload.ExecutionType,
+	}
+	systemError := func(err error) ExecuteCodeResponse {
+		response.Status = "Error"
+		response.SystemError = err.Error()
+		return response
+	}
+	fail := func(result TestCaseResult) ExecuteCodeResponse {
+		response.Status = result.Status
+		response.TestCaseResults = append(response.TestCaseResults, result)
+		return response
+	}
+
+	dir := filepath.Join("out", "generators")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return systemError(err)
+	}
+	generators := make(map[string]string, len(payload.Generators))
+	for _, g := range payload.Generators {
+		path := filepath.Join(dir, g.Name+".py")
+		if err := os.WriteFile(path, []byte(g.Code), 0644); err != nil {
+			return systemError(err)
+		}
+		generators[g.Name] = path
+	}
+
+	generated := make([]ProblemTestCase, 0, len(payload.TestScript))
+	for _, line := range payload.TestScript {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || generators[fields[0]] == "" {
+			return fail(TestCaseResult{Generator: line, Status: "Generator Error: unknown generator"})
+		}
+		input, err := runPython(generators[fields[0]], fields[1:], "")
+		if err != nil {
+			return fail(TestCaseResult{Generator: line, Status: "Generator Error: " + err.Error()})
+		}
+		generated = append(generated, ProblemTestCase{Input: input, Generator: line})
+	}
+
+	if payload.ValidatorCode != "" {
+		validatorPath := filepath.Join("out", "validator.py")
+		if err := os.WriteFile(validatorPath, []byte(payload.ValidatorCode), 0644); err != nil {
+			return systemError(err)
+		}
+		for _, tc := range append(append([]ProblemTestCase{}, payload.TestCases...), generated...) {
+			if _, err := runPython(validatorPath, nil, tc.Input); err != nil {
+				return fail(TestCaseResult{
+					ID:        tc.ID,
+					Input:     tc.Input,
+					Generator: tc.Generator,
+					Status:    "Invalid Input: " + err.Error(),
+				})
+			}
+			response.TestCaseResults = append(response.TestCaseResults, TestCaseResult{
+				ID:             tc.ID,
+				Input:          tc.Input,
+				ExpectedOutput: tc.ExpectedOutput,
+				Generator:      tc.Generator,
+				Status:         "Accepted",
+			})
+		}
+		// Generated tests are reported again below, with their expected outputs.
+		response.TestCaseResults = response.TestCaseResults[:len(payload.TestCases)]
+	}
+
+	if len(generated) == 0 {
+		return response
+	}
+
+	// Expected outputs are empty, so a run that completes is reported as a wrong answer.
+	reference := ExecutePython(ExecuteCodePayload{
+		ID:             payload.ID,
+		Code:           payload.Code,
+		TestCases:      generated,
+		RuntimeLimitMS: payload.RuntimeLimitMS,
+		MemoryLimitKB:  payload.MemoryLimitKB,
+		ExecutionType:  payload.ExecutionType,
+	})
+	if reference.SystemError != "" {
+		response.Status = reference.Status
+		response.SystemError = reference.SystemError
+		return response
+	}
+	for i, res := range reference.TestCaseResults {
+		result := TestCaseResult{
+			Input:          generated[i].Input,
+			ExpectedOutput: res.Output,
+			RuntimeMS:      res.RuntimeMS,
+			MemoryKB:       res.MemoryKB,
+			Generator:      generated[i].Generator,
+			Status:         "Accepted",
+		}
+		if res.Status != "Accepted" && res.Status != "Wrong Answer" {
+			result.Status = "Reference solution got " + res.Status
+			return fail(result)
+		}
+		response.TestCaseResults = append(response.TestCaseResults, result)
+	}
+	return response
+}
+
+// runPython runs a helper program such as a generator or validator and returns its
+// standard output. A non-zero exit is an error carrying the last line of the program's standard error.
+func runPython(path string, args []string, stdin string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), generatorTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "python3", append([]string{path}, args...)...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("timed out after %s", generatorTimeout)
+		}
+		// The last line of a Python traceback holds the exception and its message.
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg[strings.LastIndex(msg, "\n")+1:])
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Priority lanes, highest priority first, with the share of pops each one gets while all
+// of them have work. Must match the lanes the API enqueues on ("<language>:<lane>").
+var defaultLaneWeights = []laneWeight{
+	{"run", 8},
+	{"submit", 4},
+	{"validation", 2},
+	{"rejudge", 1},
+}
+
+type laneWeight struct {
+	name   string
+	weight int
+}
+
+// laneScheduler picks which lane to serve first using smooth weighted round-robin, so
+// lanes are interleaved rather than served in bursts.
+type laneScheduler struct {
+	language string
+	lanes    []laneWeight
+	current  []int
+	total    int
+}
+
+func newLaneScheduler(language string, lanes []laneWeight) *laneScheduler {
+	s := &laneScheduler{language: language, lanes: lanes, current: make([]int, len(lanes))}
+	for _, l := range lanes {
+		s.total += l.weight
+	}
+	return s
+}
+
+// next returns the Redis keys to BZPOPMIN from: the lane whose turn it is, followed by the
+// remaining lanes in priority order so a worker never idles while any lane has work.
+func (s *laneScheduler) next() []string {
+	best := 0
+	for i, l := range s.lanes {
+		s.current[i] += l.weight
+		if s.current[i] > s.current[best] {
+			best = i
+		}
+	}
+	s.current[best] -= s.total
+
+	keys := []string{s.language + ":" + s.lanes[best].name}
+	for i, l := range s.lanes {
+		if i != best {
+			keys = append(keys, s.language+":"+l.name)
+		}
+	}
+	return keys
+}
+
+// parseLaneWeights reads weights such as "run=8,submit=4" and overrides the defaults.
+// Lanes that are not mentioned keep their default weight.
+func parseLaneWeights(spec string) ([]laneWeight, error) {
+	lanes := append([]laneWeight(nil), defaultLaneWeights...)
+	if spec == "" {
+		return lanes, nil
+	}
+
+	for _, field := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		weight, err := strconv.Atoi(value)
+		if !ok || err != nil || weight < 1 {
+			return nil, fmt.Errorf("invalid lane weight %q, expected lane=N with N >= 1", field)
+		}
+
+		found := false
+		for i := range lanes {
+			if lanes[i].name == name {
+				lanes[i].weight = weight
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown lane %q", name)
+		}
+	}
+	return lanes, nil
+}
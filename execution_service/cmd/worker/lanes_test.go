@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLaneSchedulerShares(t *testing.T) {
+	s := newLaneScheduler("python", defaultLaneWeights)
+
+	// One full round serves each lane first in proportion to its weight.
+	served := make(map[string]int)
+	for i := 0; i < s.total; i++ {
+		keys := s.next()
+		if len(keys) != len(defaultLaneWeights) {
+			t.Fatalf("next() = %v, want every lane", keys)
+		}
+		served[keys[0]]++
+	}
+	for _, l := range defaultLaneWeights {
+		if got := served["python:"+l.name]; got != l.weight {
+			t.Errorf("lane %s served first %d times, want %d", l.name, got, l.weight)
+		}
+	}
+}
+
+func TestLaneSchedulerFallback(t *testing.T) {
+	s := newLaneScheduler("python", defaultLaneWeights)
+
+	want := []string{"python:run", "python:submit", "python:validation", "python:rejudge"}
+	if got := s.next(); !reflect.DeepEqual(got, want) {
+		t.Errorf("next() = %v, want %v", got, want)
+	}
+	// The lane whose turn it is comes first; the rest follow in priority order.
+	want = []string{"python:submit", "python:run", "python:validation", "python:rejudge"}
+	if got := s.next(); !reflect.DeepEqual(got, want) {
+		t.Errorf("next() = %v, want %v", got, want)
+	}
+}
+
+func TestParseLaneWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []int // weights of run, submit, validation and rejudge
+		wantErr string
+	}{
+		{name: "defaults", want: []int{8, 4, 2, 1}},
+		{name: "override", spec: "run=10, rejudge=3", want: []int{10, 4, 2, 3}},
+		{name: "unknown lane", spec: "batch=2", wantErr: "unknown lane"},
+		{name: "zero", spec: "run=0", wantErr: "invalid lane weight"},
+		{name: "not a number", spec: "run=fast", wantErr: "invalid lane weight"},
+		{name: "no weight", spec: "run", wantErr: "invalid lane weight"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lanes, err := parseLaneWeights(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]int, len(lanes))
+			for i, l := range lanes {
+				got[i] = l.weight
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("weights = %v, want %v", got, tt.want)
+			}
+			if defaultLaneWeights[0].weight != 8 {
+				t.Error("parseLaneWeights changed the defaults")
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -29,8 +30,19 @@ func main() {
 	})
 	defer rdb.Close()
 
+	workerID := os.Getenv("WORKER_ID")
+	if workerID == "" {
+		hostname, _ := os.Hostname()
+		workerID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	lanes, err := parseLaneWeights(os.Getenv("LANE_WEIGHTS"))
+	if err != nil {
+		log.Fatalf("LANE_WEIGHTS: %v", err)
+	}
+
 	// Start worker
-	startWorker(ctx, rdb, &wg)
+	startWorker(ctx, rdb, workerID, newLaneScheduler("python", lanes), &wg)
 
 	// Wait for signal
 	<-sigs
@@ -42,7 +54,21 @@ func main() {
 	log.Println("✅ Worker exited cleanly.")
 }
 
-func startWorker(ctx context.Context, rdb *redis.Client, wg *sync.WaitGroup) {
+// reportState tells the API that a job moved to a new lifecycle state.
+func reportState(ctx context.Context, rdb *redis.Client, task ExecuteCodePayload, state, workerID string) {
+	data, _ := json.Marshal(ExecutionEvent{
+		ID:            task.ID,
+		ExecutionType: task.ExecutionType,
+		State:         state,
+		WorkerID:      workerID,
+		At:            time.Now(),
+	})
+	if err := rdb.RPush(ctx, "status_queue", data).Err(); err != nil {
+		log.Printf("❌ Failed to report state %s for task ID %d: %v", state, task.ID, err)
+	}
+}
+
+func startWorker(ctx context.Context, rdb *redis.Client, workerID string, lanes *laneScheduler, wg *sync.WaitGroup) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -54,29 +80,46 @@ func startWorker(ctx context.Context, rdb *redis.Client, wg *sync.WaitGroup) {
 				log.Println("🛑 Worker context canceled. Exiting...")
 				return
 			default:
-				res, err := rdb.BLPop(ctx, 5*time.Second, "python").Result()
+				// BZPOPMIN checks the keys in order, starting with the lane whose turn it is. Within
+				// a lane the API scores jobs so that users are served round-robin.
+				res, err := rdb.BZPopMin(ctx, 5*time.Second, lanes.next()...).Result()
 				if err != nil {
 					if err == redis.Nil {
 						continue
 					}
 					if ctx.Err() != nil {
-						log.Println("Context canceled during BLPOP")
+						log.Println("Context canceled during BZPOPMIN")
 						return
 					}
-					log.Printf("BLPOP error: %v", err)
+					log.Printf("BZPOPMIN error: %v", err)
 					time.Sleep(1 * time.Second)
 					continue
 				}
 
 				var task ExecuteCodePayload
-				if err := json.Unmarshal([]byte(res[1]), &task); err != nil {
+				member, _ := res.Member.(string)
+				if err := json.Unmarshal([]byte(member), &task); err != nil {
 					log.Printf("Invalid task JSON: %v", err)
 					continue
 				}
 
 				// log.Printf("🔧 Processing task ID %d: %s", task.ID, task.Name)
 
-				result := ExecutePython(task)
+				startedAt := time.Now()
+				reportState(ctx, rdb, task, StateRunning, workerID)
+
+				var result ExecuteCodeResponse
+				if task.ExecutionType == ExecutionTypeGeneration {
+					result = GenerateTests(task)
+				} else {
+					result = ExecutePython(task)
+					if result.Status == "Accepted" && len(task.Solutions) > 0 {
+						result.SolutionResults = RunSolutions(task)
+					}
+				}
+				result.WorkerID = workerID
+				result.StartedAt = startedAt
+				result.FinishedAt = time.Now()
 
 				data, _ := json.Marshal(result)
 				if err := rdb.RPush(ctx, "results_queue", data).Err(); err != nil {
@@ -93,6 +136,26 @@ type ProblemTestCase struct {
 	ID             int    `json:"id"`
 	Input          string `json:"input"`
 	ExpectedOutput string `json:"expected_output"`
+	Generator      string `json:"generator,omitempty"`
+}
+
+type ProblemSolution struct {
+	Name string `json:"name"`
+	Code string `json:"code"`
+	Tag  string `json:"tag"`
+}
+
+type SolutionResult struct {
+	Name            string           `json:"name"`
+	Tag             string           `json:"tag"`
+	Status          string           `json:"status"`
+	TestCaseResults []TestCaseResult `json:"test_case_results"`
+	SystemError     string           `json:"system_error,omitempty"` // the solution could not be judged
+}
+
+type ProblemGenerator struct {
+	Name string `json:"name"`
+	Code string `json:"code"`
 }
 
 type TestCaseResult struct {
@@ -101,23 +164,50 @@ type TestCaseResult struct {
 	Output         string `json:"output"`
 	ExpectedOutput string `json:"expected_output"`
 	RuntimeMS      int    `json:"runtime_ms"`
+	CPUTimeMS      int    `json:"cpu_time_ms,omitempty"` // last sampled for runs that were killed
 	MemoryKB       int    `json:"memory_kb"`
 	Status         string `json:"status"` // TLE, MLE, Acccepted, Wrong Answer, $Error.message
+	Generator      string `json:"generator,omitempty"`
 }
 
 type ExecuteCodePayload struct {
-	ID             int               `json:"id"`
-	LanguageID     int               `json:"language_id"`
-	Code           string            `json:"code"`
-	TestCases      []ProblemTestCase `json:"test_cases"`
-	RuntimeLimitMS int               `json:"runtime_limit_ms"`
-	MemoryLimitKB  int               `json:"memory_limit_kb"`
-	ExecutionType  string            `json:"execution_type"` // Run, Submit, Validation
+	ID             int                `json:"id"`
+	UserID         int                `json:"user_id"`
+	LanguageID     int                `json:"language_id"`
+	Code           string             `json:"code"`
+	TestCases      []ProblemTestCase  `json:"test_cases"`
+	RuntimeLimitMS int                `json:"runtime_limit_ms"`
+	MemoryLimitKB  int                `json:"memory_limit_kb"`
+	CheckerCode    string             `json:"checker_code"` // judges outputs instead of an exact match
+	Generators     []ProblemGenerator `json:"generators"`
+	TestScript     []string           `json:"test_script"` // "generator arg..." per generated test
+	ValidatorCode  string             `json:"validator_code"`
+	Solutions      []ProblemSolution  `json:"solutions"`      // validation only, run after the reference solution
+	ExecutionType  string             `json:"execution_type"` // Run, Submit, Validation
 }
 
 type ExecuteCodeResponse struct {
 	ID              int              `json:"id"`
 	Status          string           `json:"status"` // TLE, MLE, Acccepted, Wrong Answer, $Error.message
 	TestCaseResults []TestCaseResult `json:"test_case_results"`
-	ExecutionType   string           `json:"execution_type"` // Run, Submit, Validation
+	ExecutionType   string           `json:"execution_type"`         // Run, Submit, Validation
+	SystemError     string           `json:"system_error,omitempty"` // the job could not be judged, not the code's fault
+	SolutionResults []SolutionResult `json:"solution_results,omitempty"`
+	WorkerID        string           `json:"worker_id"`
+	StartedAt       time.Time        `json:"started_at"`
+	FinishedAt      time.Time        `json:"finished_at"`
+}
+
+const ExecutionTypeGeneration = "generation"
+
+// Lifecycle states reported on the status queue while a job is processed.
+// Compiled languages will also report "Compiling" before StateRunning.
+const StateRunning = "Running"
+
+type ExecutionEvent struct {
+	ID            int       `json:"id"`
+	ExecutionType string    `json:"execution_type"`
+	State         string    `json:"state"`
+	WorkerID      string    `json:"worker_id"`
+	At            time.Time `json:"at"`
 }
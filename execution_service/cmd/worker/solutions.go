@@ -0,0 +1,22 @@
+package main
+
+// RunSolutions runs the extra solutions of a problem on its tests, once its reference
+// solution has passed them, so the API can check that wrong solutions are killed. A
+// solution is not run on the remaining tests once it has timed out, so that slow
+// solutions do not hold the worker for the time limit of every test.
+func RunSolutions(payload ExecuteCodePayload) []SolutionResult {
+	results := make([]SolutionResult, 0, len(payload.Solutions))
+	for _, s := range payload.Solutions {
+		run := payload
+		run.Code = s.Code
+		res := executePython(run, true)
+		results = append(results, SolutionResult{
+			Name:            s.Name,
+			Tag:             s.Tag,
+			Status:          res.Status,
+			TestCaseResults: res.TestCaseResults,
+			SystemError:     res.SystemError,
+		})
+	}
+	return results
+}
@@ -5,7 +5,7 @@ import type { ProblemDetail, ProblemInfo } from "../types";
 export const getProblems = async (): Promise<ProblemInfo[]> => {
     try {
         const response = await axios.get('/api/problems');
-        return response.data;
+        return response.data.problems;
     } catch (error: any) {
         throw new Error(error.response?.data?.message || 'An error occurred while fetching problems');
     }
@@ -2,7 +2,7 @@ import axios from './axios';
 import type { SubmissionPayload, SubmissionResult, TestCaseResult } from '../types';
 
 // Submit code for a problem
-export const submitCode = async (payload: SubmissionPayload): Promise<{ submission_id: number }> => {
+export const submitCode = async (payload: SubmissionPayload): Promise<{ submission_id: number; queue_position: number; estimated_wait_seconds: number }> => {
     try {
         const response = await axios.post(`/api/submit`, payload);
         return response.data;
@@ -34,8 +34,8 @@ export const getSubmissionResult = async (submissionId: number): Promise<Submiss
 // Get the result of a specific run
 export const getRunResult = async (runId: number): Promise<TestCaseResult[]> => {
     try {
-        const response = await axios.get(`/a[i/runs/${runId}`);
-        return response.data;
+        const response = await axios.get(`/api/runs/${runId}`);
+        return response.data.test_case_results;
     } catch (error: any) {
         throw new Error(error.response?.data?.message || 'An error occurred while fetching the run result');
     }